@@ -5,15 +5,185 @@ import (
 	"fmt"
 	"github.com/pkg/errors"
 	task2 "github.com/wprzechrzta/taskl/cmd/taskl/task"
+	"io/ioutil"
 	"os"
+	"os/exec"
 	"strconv"
+	"strings"
+	"time"
 )
 
 type BasicCommand struct {
 	fs         *flag.FlagSet
 	repository *task2.Repository
 	board      string
-	taskId     int
+	taskIds    []int
+	// local makes taskIds board local numbers, all selects every task on the board
+	local bool
+	all   bool
+}
+
+// boardFlags registers flags scoping command to single board
+func (c *BasicCommand) boardFlags() {
+	c.fs.StringVar(&c.board, "b", "", "Board name tasks belongs to")
+	c.fs.BoolVar(&c.local, "local", false, "Task ids are board local numbers, requires -b")
+	c.fs.BoolVar(&c.all, "all", false, "Apply to every task on the board, requires -b")
+}
+
+// parse reads flags and task id arguments, prefix is used in error messages
+func (c *BasicCommand) parse(args []string, prefix string) error {
+	if err := c.fs.Parse(args); err != nil {
+		return errors.WithMessagef(err, "%s: Failed parse ", c.fs.Name())
+	}
+	if (c.local || c.all) && c.board == "" {
+		return fmt.Errorf("%s: Board is required, use -b", prefix)
+	}
+	if c.all {
+		return nil
+	}
+
+	if len(c.fs.Args()) < 1 {
+		return fmt.Errorf("%s: Missing task id", prefix)
+	}
+	ids, err := parseIds(c.fs.Args())
+	if err != nil {
+		return errors.WithMessagef(err, "%s", prefix)
+	}
+	c.taskIds = ids
+	return nil
+}
+
+// parseInterspersed parses flags given before, between or after positional
+// arguments and returns the positional ones, everything after -- is kept as is
+func parseInterspersed(fs *flag.FlagSet, args []string) ([]string, error) {
+	var positional []string
+	for {
+		if err := fs.Parse(args); err != nil {
+			return nil, err
+		}
+		rest := fs.Args()
+		if consumed := len(args) - len(rest); consumed > 0 && args[consumed-1] == "--" {
+			return append(positional, rest...), nil
+		}
+		if len(rest) == 0 {
+			return positional, nil
+		}
+		positional = append(positional, rest[0])
+		args = rest[1:]
+	}
+}
+
+// maxRangeIds limits number of ids single range can expand to
+const maxRangeIds = 10000
+
+// parseIds reads task ids and inclusive ranges like 7-12, duplicates are dropped
+func parseIds(args []string) ([]int, error) {
+	var ids []int
+	seen := map[int]bool{}
+	add := func(id int) {
+		if !seen[id] {
+			seen[id] = true
+			ids = append(ids, id)
+		}
+	}
+	for _, arg := range args {
+		from, to := arg, arg
+		if idx := strings.Index(arg, "-"); idx > 0 {
+			from, to = arg[:idx], arg[idx+1:]
+		}
+		first, err := strconv.Atoi(from)
+		if err != nil {
+			return nil, fmt.Errorf("Task id should be integer value or range, provided: %v", arg)
+		}
+		last, err := strconv.Atoi(to)
+		if err != nil {
+			return nil, fmt.Errorf("Task id should be integer value or range, provided: %v", arg)
+		}
+		if first > last {
+			return nil, fmt.Errorf("Invalid range: %v", arg)
+		}
+		if last-first >= maxRangeIds {
+			return nil, fmt.Errorf("Range %v is too large, at most %d ids are allowed", arg, maxRangeIds)
+		}
+		for id := first; id <= last; id++ {
+			add(id)
+		}
+	}
+	return ids, nil
+}
+
+// selectedIds returns ids of tasks command should be applied to, in bulk
+// mode only tasks which can be moved to given status are selected
+func (c *BasicCommand) selectedIds(to task2.Status) ([]int, error) {
+	if c.all {
+		tasks, err := c.repository.BoardTasks(c.board)
+		if err != nil {
+			return nil, err
+		}
+		var ids []int
+		for _, t := range tasks {
+			if !t.IsNote && t.Status.CanTransition(to) {
+				ids = append(ids, t.Id)
+			}
+		}
+		return ids, nil
+	}
+	if c.local {
+		var ids []int
+		for _, number := range c.taskIds {
+			id, err := c.repository.ResolveLocalId(c.board, number)
+			if err != nil {
+				return nil, err
+			}
+			ids = append(ids, id)
+		}
+		return ids, nil
+	}
+	return c.taskIds, nil
+}
+
+// apply moves every selected task to given status in single repository update
+func (c *BasicCommand) apply(to task2.Status, done string) error {
+	return c.applyWith(to, done, c.repository.TransitionAll)
+}
+
+// applyWith works like apply, selected tasks are updated by transition
+func (c *BasicCommand) applyWith(to task2.Status, done string, transition func(ids []int, to task2.Status, board string) ([]task2.Result, error)) error {
+	ids, err := c.selectedIds(to)
+	if err != nil {
+		return err
+	}
+	if len(ids) == 0 {
+		fmt.Printf("No tasks to update on board: %s\n", c.board)
+		return nil
+	}
+	results, err := transition(ids, to, c.board)
+	if err != nil {
+		return err
+	}
+	return report(results, done)
+}
+
+// report prints outcome for every task, error is returned when any of them failed
+func report(results []task2.Result, done string) error {
+	return reportAs(results, done+" task")
+}
+
+// reportAs prints outcome of every task labelled with done, e.g. "Converted to note: 2"
+func reportAs(results []task2.Result, done string) error {
+	var failed int
+	for _, r := range results {
+		if r.Err != nil {
+			failed++
+			fmt.Printf("Failed task: %d, %v\n", r.Id, r.Err)
+			continue
+		}
+		fmt.Printf("%s: %d \n", done, r.Id)
+	}
+	if failed > 0 {
+		return fmt.Errorf("%d of %d tasks failed", failed, len(results))
+	}
+	return nil
 }
 
 type ArgRunner interface {
@@ -25,10 +195,20 @@ type ArgRunner interface {
 type ListCommand struct {
 	fs         *flag.FlagSet
 	repository *task2.Repository
+	local      bool
+	starred    bool
+	overdue    bool
+	dueWithin  string
+	// labels are +tag and @context filters given as arguments
+	labels []string
 }
 
 func NewListCommand(repo *task2.Repository) *ListCommand {
 	lc := &ListCommand{fs: flag.NewFlagSet("listall", flag.PanicOnError), repository: repo}
+	lc.fs.BoolVar(&lc.local, "local", false, "Show board local task numbers instead of ids")
+	lc.fs.BoolVar(&lc.starred, "starred", false, "Show only starred tasks")
+	lc.fs.BoolVar(&lc.overdue, "overdue", false, "Show only overdue tasks")
+	lc.fs.StringVar(&lc.dueWithin, "due-within", "", "Show only tasks due within given span, e.g. 3d or 2w")
 	return lc
 }
 
@@ -36,18 +216,54 @@ func (l *ListCommand) Init(args []string) error {
 	if err := l.fs.Parse(args); err != nil {
 		return errors.WithMessagef(err, "%s: Failed parse ", l.Name())
 	}
+	if l.dueWithin != "" {
+		if _, err := task2.ParseDays(l.dueWithin); err != nil {
+			return errors.WithMessagef(err, "%s", l.Name())
+		}
+	}
+	l.labels = nil
+	for _, arg := range l.fs.Args() {
+		if !task2.IsTagToken(arg) {
+			return fmt.Errorf("%s: Expected +tag or @context filter, provided: %s", l.Name(), arg)
+		}
+		l.labels = append(l.labels, arg)
+	}
 	return nil
 }
 
+// filter keeps tasks matching list flags
+func (l *ListCommand) filter(tl task2.TaskList, now time.Time) task2.TaskList {
+	if l.starred {
+		tl = tl.Filter(func(t task2.Task) bool { return t.IsStarred })
+	}
+	if l.overdue {
+		tl = tl.Filter(func(t task2.Task) bool { return t.IsOverdue(now) })
+	}
+	if l.dueWithin != "" {
+		days, _ := task2.ParseDays(l.dueWithin)
+		tl = tl.Filter(func(t task2.Task) bool { return t.DueWithin(days, now) })
+	}
+	if len(l.labels) > 0 {
+		tl = tl.Filter(func(t task2.Task) bool { return t.MatchLabels(l.labels) })
+	}
+	return tl
+}
+
 func (l *ListCommand) Run() error {
 	tl, err := l.repository.GetAll()
 	if err != nil {
 		return errors.WithMessagef(err, "%s: Failed to fetch Tasks ", l.Name())
 	}
-	summary, err := calculateSummary(tl)
+	now := time.Now()
+	filtered := l.filter(*tl, now)
+	summary, err := calculateSummaryAt(&filtered, now)
 	if err != nil {
 		return err
 	}
+	summary.LocalNumbers = l.local
+	summary.numberLocally(*tl)
+	// prerequisites may be filtered out of the listing
+	summary.Waiting = tl.Waiting()
 	renderOutput(os.Stdout, summary)
 	return err
 }
@@ -59,39 +275,35 @@ func (l *ListCommand) Name() string {
 //Begin command
 type BeginCommand struct {
 	*BasicCommand
+	// force starts tasks waiting for unfinished prerequisites with a warning
+	force bool
 }
 
 func NewBeginTaskCommand(repo *task2.Repository) *BeginCommand {
-	c := &BeginCommand{&BasicCommand{fs: flag.NewFlagSet("b", flag.PanicOnError), repository: repo}}
-	c.fs.StringVar(&c.board, "b", "My Board", "Board repo attach task")
+	c := &BeginCommand{BasicCommand: &BasicCommand{fs: flag.NewFlagSet("b", flag.PanicOnError), repository: repo}}
+	c.boardFlags()
+	c.fs.BoolVar(&c.force, "force", false, "Start tasks even when their prerequisites are not finished")
 	return c
 }
 
 func (b *BeginCommand) Init(args []string) error {
-	if err := b.fs.Parse(args); err != nil {
-		return errors.WithMessagef(err, "%s: Failed parse ", b.Name())
-	}
-
-	if len(b.fs.Args()) < 1 {
-		return fmt.Errorf("BeginComand: Missing task id")
-	}
-
-	taskIdStr := b.fs.Arg(0)
-	if taskId, err := strconv.Atoi(taskIdStr); err != nil {
-		return errors.WithMessagef(err, "BeginCommand: Task id should be integer value, provided: %v", taskIdStr)
-	} else {
-		b.taskId = taskId
-	}
-
-	return nil
+	return b.parse(args, "BeginCommand")
 }
 
 func (b *BeginCommand) Run() error {
-	if err := b.repository.Start(b.taskId); err != nil {
-		return err
+	if !b.force {
+		return b.applyWith(task2.StatusInProgress, "Started", func(ids []int, _ task2.Status, board string) ([]task2.Result, error) {
+			return b.repository.StartAll(ids, board)
+		})
 	}
-	fmt.Printf("Started task: %d \n", b.taskId)
-	return nil
+	return b.applyWith(task2.StatusInProgress, "Started", func(ids []int, to task2.Status, board string) ([]task2.Result, error) {
+		for _, id := range ids {
+			if waiting, err := b.repository.Unfinished(id); err == nil && len(waiting) > 0 {
+				fmt.Printf("Warning: task %d waits for %s\n", id, formatIds(waiting))
+			}
+		}
+		return b.repository.TransitionAll(ids, to, board)
+	})
 }
 
 func (b *BeginCommand) Name() string {
@@ -100,7 +312,7 @@ func (b *BeginCommand) Name() string {
 
 func NewCompleteCommand(repo *task2.Repository) *CompleteCommand {
 	c := &CompleteCommand{&BasicCommand{fs: flag.NewFlagSet("c", flag.PanicOnError), repository: repo}}
-	c.fs.StringVar(&c.board, "b", "My Board", "Board name tasks belongs to ")
+	c.boardFlags()
 	return c
 }
 
@@ -109,29 +321,11 @@ type CompleteCommand struct {
 }
 
 func (b *CompleteCommand) Init(args []string) error {
-	if err := b.fs.Parse(args); err != nil {
-		return errors.WithMessagef(err, "%s: Failed parse ", b.Name())
-	}
-
-	if len(b.fs.Args()) < 1 {
-		return fmt.Errorf("CompleteComand: Missing task id")
-	}
-
-	taskIdStr := b.fs.Arg(0)
-	if taskId, err := strconv.Atoi(taskIdStr); err != nil {
-		return errors.WithMessagef(err, "CompleteCommand: Task id should be integer value, provided: %v", taskIdStr)
-	} else {
-		b.taskId = taskId
-	}
-	return nil
+	return b.parse(args, "CompleteCommand")
 }
 
 func (b *CompleteCommand) Run() error {
-	if err := b.repository.Complete(b.taskId); err != nil {
-		return err
-	}
-	fmt.Printf("Checked task: %d \n", b.taskId)
-	return nil
+	return b.apply(task2.StatusDone, "Checked")
 }
 
 func (b *CompleteCommand) Name() string {
@@ -143,12 +337,39 @@ type CreateTaskCommand struct {
 	repository *task2.Repository
 	board      string
 	body       string
+	priority   int
+	note       bool
+	dueInput   string
+	due        time.Time
+	tags       []string
+	contexts   []string
+	repeat     string
+	recurrence *task2.Recurrence
+	estInput   string
+	estimate   time.Duration
+	parent     int
+	// boardSet is true when board was given explicitly, subtasks inherit parent boards otherwise
+	boardSet bool
 }
 
 // NewCreateTaskCommand creates new task
 func NewCreateTaskCommand(repo *task2.Repository) *CreateTaskCommand {
-	tc := &CreateTaskCommand{fs: flag.NewFlagSet("t", flag.PanicOnError), repository: repo}
-	tc.fs.StringVar(&tc.board, "b", "My Board", "Board repo attach task")
+	return newCreateCommand("t", repo, false)
+}
+
+// NewCreateNoteCommand creates new note
+func NewCreateNoteCommand(repo *task2.Repository) *CreateTaskCommand {
+	return newCreateCommand("note", repo, true)
+}
+
+func newCreateCommand(name string, repo *task2.Repository, note bool) *CreateTaskCommand {
+	tc := &CreateTaskCommand{fs: flag.NewFlagSet(name, flag.PanicOnError), repository: repo, note: note}
+	tc.fs.StringVar(&tc.board, "b", task2.DefaultBoard, "Board repo attach task")
+	tc.fs.IntVar(&tc.priority, "p", 0, "Task priority 1-3, can be also given inline as p:N")
+	tc.fs.StringVar(&tc.dueInput, "due", "", "Due date, e.g. 2026-11-01, tomorrow, fri, \"next fri\", 3d")
+	tc.fs.StringVar(&tc.repeat, "repeat", "", "Recurrence, e.g. daily, weekdays, \"weekly mon,thu\", monthly, \"every 3d\"")
+	tc.fs.StringVar(&tc.estInput, "est", "", "Estimated effort, e.g. 90m, 2h or 1h30m")
+	tc.fs.IntVar(&tc.parent, "parent", 0, "Id of parent task, subtask inherits its boards unless -b is given")
 	return tc
 }
 
@@ -157,25 +378,87 @@ func (tc *CreateTaskCommand) Name() string {
 }
 
 func (tc *CreateTaskCommand) Init(args []string) error {
-	if err := tc.fs.Parse(args); err != nil {
+	words, err := parseInterspersed(tc.fs, args)
+	if err != nil {
 		return errors.WithMessagef(err, "Failed repository parse %s", tc.Name())
 	}
-	if len(tc.fs.Args()) < 1 {
+	if len(words) < 1 {
+		return fmt.Errorf("TaskComand: Missing task description")
+	}
+	if tc.parent < 0 {
+		return fmt.Errorf("TaskComand: Parent id should be positive, provided: %d", tc.parent)
+	}
+	tc.boardSet = false
+	tc.fs.Visit(func(f *flag.Flag) {
+		tc.boardSet = tc.boardSet || f.Name == "b"
+	})
+	body, priority, err := task2.ExtractPriority(strings.Join(words, " "))
+	if err != nil {
+		return errors.WithMessage(err, "TaskComand")
+	}
+	body, tc.tags, tc.contexts = task2.ExtractTags(body)
+	if body == "" {
 		return fmt.Errorf("TaskComand: Missing task description")
 	}
-	tc.body = tc.fs.Arg(0)
+	tc.body = body
+	if priority > 0 {
+		tc.priority = priority
+	}
+	if tc.priority != 0 {
+		if err := task2.ValidatePriority(tc.priority); err != nil {
+			return errors.WithMessage(err, "TaskComand")
+		}
+	}
+	if tc.dueInput != "" {
+		due, err := task2.ParseDue(tc.dueInput, time.Now())
+		if err != nil {
+			return errors.WithMessage(err, "TaskComand")
+		}
+		tc.due = due
+	}
+	tc.estimate = 0
+	if tc.estInput != "" {
+		if tc.estimate, err = task2.ParseEstimate(tc.estInput); err != nil {
+			return errors.WithMessage(err, "TaskComand")
+		}
+	}
+	tc.recurrence = nil
+	if tc.repeat != "" {
+		if tc.note {
+			return fmt.Errorf("TaskComand: Notes can not recur")
+		}
+		recurrence, err := task2.ParseRecurrence(tc.repeat)
+		if err != nil {
+			return errors.WithMessage(err, "TaskComand")
+		}
+		tc.recurrence = &recurrence
+	}
 	return nil
 }
 
 func (tc *CreateTaskCommand) Run() error {
 	Log("Running command: %s, BoardName: %s", tc.Name(), tc.board)
 	var t task2.Task
-	t.Boards = append(t.Boards, tc.board)
+	if tc.parent == 0 || tc.boardSet {
+		t.Boards = append(t.Boards, tc.board)
+	}
+	t.ParentId = tc.parent
 	t.Description = tc.body
+	t.Priority = tc.priority
+	t.IsNote = tc.note
+	t.DueDate = tc.due
+	t.Recurrence = tc.recurrence
+	t.Estimate = tc.estimate
+	t.Tags = tc.tags
+	t.Contexts = tc.contexts
 	newtask, err := tc.repository.Create(t)
 	if err != nil {
 		return err
 	}
+	if tc.note {
+		fmt.Printf("Created note: %d\n", newtask.Id)
+		return nil
+	}
 	fmt.Printf("Created task: %d\n", newtask.Id)
 	return nil
 }
@@ -186,75 +469,1136 @@ type CancelTaskCommand struct {
 
 func NewCancelCommand(repository *task2.Repository) *CancelTaskCommand {
 	c := &CancelTaskCommand{&BasicCommand{fs: flag.NewFlagSet("cancel", flag.PanicOnError), repository: repository}}
-	c.fs.StringVar(&c.board, "b", "My Board", "Board name tasks belongs to ")
+	c.boardFlags()
 	return c
 }
 
 func (c *CancelTaskCommand) Init(args []string) error {
-	if err := c.fs.Parse(args); err != nil {
-		return errors.WithMessagef(err, "%s: Failed parse ", c.Name())
-	}
-
-	if len(c.fs.Args()) < 1 {
-		return fmt.Errorf("CancelComand: Missing task id")
-	}
-
-	taskIdStr := c.fs.Arg(0)
-	if taskId, err := strconv.Atoi(taskIdStr); err != nil {
-		return errors.WithMessagef(err, "CancelCommand: Task id should be integer value, provided: %v", taskIdStr)
-	} else {
-		c.taskId = taskId
-	}
-	return nil
+	return c.parse(args, "CancelCommand")
 }
 
 func (c *CancelTaskCommand) Run() error {
-	if err := c.repository.Cancel(c.taskId); err != nil {
-		return err
-	}
-	fmt.Printf("Canceled task: %d \n", c.taskId)
-	return nil
+	return c.apply(task2.StatusCancelled, "Canceled")
 }
 
 func (c *CancelTaskCommand) Name() string {
 	return c.fs.Name()
 }
 
+// NewDeleteCommand moves tasks into archive, with -purge they are removed permanently
 func NewDeleteCommand(repository *task2.Repository) *DeleteCommand {
-	dc := &DeleteCommand{&BasicCommand{fs: flag.NewFlagSet("d", flag.PanicOnError), repository: repository}}
+	dc := &DeleteCommand{BasicCommand: &BasicCommand{fs: flag.NewFlagSet("d", flag.PanicOnError), repository: repository}}
+	dc.fs.BoolVar(&dc.purge, "purge", false, "Remove tasks permanently instead of archiving")
 	return dc
 }
 
 type DeleteCommand struct {
 	*BasicCommand
+	purge bool
 }
 
 func (d *DeleteCommand) Init(args []string) error {
-	if err := d.fs.Parse(args); err != nil {
-		return errors.WithMessagef(err, "%s: Failed parse ", d.Name())
+	return d.parse(args, "DeleteCommand")
+}
+
+func (d *DeleteCommand) Run() error {
+	if !d.purge {
+		results, err := d.repository.ArchiveAll(d.taskIds, "")
+		if err != nil {
+			return err
+		}
+		return report(results, "Archived")
+	}
+	results, err := d.repository.DeleteAll(d.taskIds, "")
+	if err != nil {
+		return err
+	}
+	return report(results, "Deleted")
+}
+
+func (d *DeleteCommand) Name() string {
+	return d.fs.Name()
+}
+
+type MigrateCommand struct {
+	fs     *flag.FlagSet
+	config AppConfig
+	from   string
+}
+
+// NewMigrateCommand imports json storage into sqlite database
+func NewMigrateCommand(config AppConfig) *MigrateCommand {
+	mc := &MigrateCommand{fs: flag.NewFlagSet("migrate", flag.PanicOnError), config: config}
+	mc.fs.StringVar(&mc.from, "from", config.StoragePath, "Directory containing taskl.json to import")
+	return mc
+}
+
+func (m *MigrateCommand) Init(args []string) error {
+	if err := m.fs.Parse(args); err != nil {
+		return errors.WithMessagef(err, "%s: Failed parse ", m.Name())
+	}
+	return nil
+}
+
+func (m *MigrateCommand) Run() error {
+	source, err := task2.NewFileStore(m.from)
+	if err != nil {
+		return err
+	}
+	tl, err := source.List()
+	if err != nil {
+		return errors.WithMessagef(err, "%s: Failed to read json storage", m.Name())
+	}
+	target, err := task2.NewSQLiteStore(m.config.StoragePath)
+	if err != nil {
+		return err
+	}
+	defer target.Close()
+
+	if err := importInto(target, tl.Tasks); err != nil {
+		return err
 	}
 
-	if len(d.fs.Args()) < 1 {
-		return fmt.Errorf("DeleteComand: Missing task id")
+	archive, err := task2.NewFileArchive(m.from)
+	if err != nil {
+		return err
+	}
+	archived, err := archive.List()
+	if err != nil {
+		return errors.WithMessagef(err, "%s: Failed to read json archive", m.Name())
+	}
+	if len(archived.Tasks) == 0 {
+		return nil
+	}
+	targetArchive, err := task2.NewSQLiteArchive(m.config.StoragePath)
+	if err != nil {
+		return err
 	}
+	defer targetArchive.Close()
+	return importInto(targetArchive, archived.Tasks)
+}
 
-	taskIdStr := d.fs.Arg(0)
-	if taskId, err := strconv.Atoi(taskIdStr); err != nil {
-		return errors.WithMessagef(err, "DeleteCommand: Task id should be integer value, provided: %v", taskIdStr)
-	} else {
-		d.taskId = taskId
+func importInto(target *task2.SQLiteStore, tasks []task2.Task) error {
+	imported, skipped, err := target.Import(tasks)
+	if err != nil {
+		return err
+	}
+	fmt.Printf("Imported %d tasks into %s\n", len(imported), target.Path)
+	if len(skipped) > 0 {
+		fmt.Printf("Skipped %d tasks already present: %v\n", len(skipped), skipped)
 	}
 	return nil
 }
 
-func (d *DeleteCommand) Run() error {
-	if err := d.repository.Delete(d.taskId); err != nil {
+func (m *MigrateCommand) Name() string {
+	return m.fs.Name()
+}
+
+type ImportCommand struct {
+	fs         *flag.FlagSet
+	repository *task2.Repository
+	format     string
+	path       string
+}
+
+// NewImportCommand imports tasks stored by other tools
+func NewImportCommand(repository *task2.Repository) *ImportCommand {
+	ic := &ImportCommand{fs: flag.NewFlagSet("import", flag.PanicOnError), repository: repository}
+	ic.fs.StringVar(&ic.format, "format", "taskbook", "Format of imported file, supported: taskbook")
+	return ic
+}
+
+func (i *ImportCommand) Init(args []string) error {
+	if err := i.fs.Parse(args); err != nil {
+		return errors.WithMessagef(err, "%s: Failed parse ", i.Name())
+	}
+	if i.format != "taskbook" {
+		return fmt.Errorf("ImportCommand: Unsupported format: %s", i.format)
+	}
+	if len(i.fs.Args()) < 1 {
+		return fmt.Errorf("ImportCommand: Missing file to import")
+	}
+	i.path = i.fs.Arg(0)
+	return nil
+}
+
+func (i *ImportCommand) Run() error {
+	data, err := ioutil.ReadFile(i.path)
+	if err != nil {
+		return errors.WithMessagef(err, "%s: Failed to read file", i.Name())
+	}
+	results, err := i.repository.ImportTaskbook(data)
+	if err != nil {
 		return err
 	}
-	fmt.Printf("Deleted task: %d \n", d.taskId)
+	var imported, skipped int
+	for _, r := range results {
+		switch {
+		case r.Skipped != "":
+			skipped++
+			fmt.Printf("Skipped item: %d, %s\n", r.SourceId, r.Skipped)
+		case r.Id != r.SourceId:
+			imported++
+			fmt.Printf("Imported item: %d as task: %d, id was already taken\n", r.SourceId, r.Id)
+		default:
+			imported++
+		}
+	}
+	fmt.Printf("Imported %d tasks, skipped %d\n", imported, skipped)
 	return nil
 }
 
-func (d *DeleteCommand) Name() string {
-	return d.fs.Name()
+func (i *ImportCommand) Name() string {
+	return i.fs.Name()
+}
+
+type ReopenCommand struct {
+	*BasicCommand
+}
+
+// NewReopenCommand moves finished or blocked task back to pending
+func NewReopenCommand(repository *task2.Repository) *ReopenCommand {
+	c := &ReopenCommand{&BasicCommand{fs: flag.NewFlagSet("reopen", flag.PanicOnError), repository: repository}}
+	c.boardFlags()
+	return c
+}
+
+func (r *ReopenCommand) Init(args []string) error {
+	return r.parse(args, "ReopenCommand")
+}
+
+func (r *ReopenCommand) Run() error {
+	return r.apply(task2.StatusPending, "Reopened")
+}
+
+func (r *ReopenCommand) Name() string {
+	return r.fs.Name()
+}
+
+type BlockCommand struct {
+	*BasicCommand
+}
+
+// NewBlockCommand marks task as blocked, reopen unblocks it
+func NewBlockCommand(repository *task2.Repository) *BlockCommand {
+	c := &BlockCommand{&BasicCommand{fs: flag.NewFlagSet("block", flag.PanicOnError), repository: repository}}
+	c.boardFlags()
+	return c
+}
+
+func (b *BlockCommand) Init(args []string) error {
+	return b.parse(args, "BlockCommand")
+}
+
+func (b *BlockCommand) Run() error {
+	return b.apply(task2.StatusBlocked, "Blocked")
+}
+
+func (b *BlockCommand) Name() string {
+	return b.fs.Name()
+}
+
+type PauseCommand struct {
+	*BasicCommand
+}
+
+// NewPauseCommand stops time tracking of task in progress, b resumes it
+func NewPauseCommand(repository *task2.Repository) *PauseCommand {
+	c := &PauseCommand{&BasicCommand{fs: flag.NewFlagSet("pause", flag.PanicOnError), repository: repository}}
+	c.boardFlags()
+	return c
+}
+
+func (p *PauseCommand) Init(args []string) error {
+	return p.parse(args, "PauseCommand")
+}
+
+func (p *PauseCommand) Run() error {
+	return p.apply(task2.StatusPaused, "Paused")
+}
+
+func (p *PauseCommand) Name() string {
+	return p.fs.Name()
+}
+
+type PriorityCommand struct {
+	*BasicCommand
+	priority int
+}
+
+// NewPriorityCommand changes priority of tasks, last argument is the priority
+func NewPriorityCommand(repository *task2.Repository) *PriorityCommand {
+	return &PriorityCommand{BasicCommand: &BasicCommand{fs: flag.NewFlagSet("priority", flag.PanicOnError), repository: repository}}
+}
+
+func (p *PriorityCommand) Init(args []string) error {
+	if err := p.fs.Parse(args); err != nil {
+		return errors.WithMessagef(err, "%s: Failed parse ", p.Name())
+	}
+	if p.fs.NArg() < 2 {
+		return fmt.Errorf("PriorityCommand: Expected task ids and priority")
+	}
+	last := p.fs.Arg(p.fs.NArg() - 1)
+	priority, err := strconv.Atoi(last)
+	if err != nil {
+		return fmt.Errorf("PriorityCommand: Priority should be integer value, provided: %v", last)
+	}
+	if err := task2.ValidatePriority(priority); err != nil {
+		return errors.WithMessage(err, "PriorityCommand")
+	}
+	p.priority = priority
+	ids, err := parseIds(p.fs.Args()[:p.fs.NArg()-1])
+	if err != nil {
+		return errors.WithMessage(err, "PriorityCommand")
+	}
+	p.taskIds = ids
+	return nil
+}
+
+func (p *PriorityCommand) Run() error {
+	results, err := p.repository.SetPriority(p.taskIds, p.priority)
+	if err != nil {
+		return err
+	}
+	return report(results, "Updated priority of")
+}
+
+func (p *PriorityCommand) Name() string {
+	return p.fs.Name()
+}
+
+type StarCommand struct {
+	*BasicCommand
+	starred bool
+}
+
+// NewStarCommand marks tasks as focus items
+func NewStarCommand(repository *task2.Repository) *StarCommand {
+	return &StarCommand{BasicCommand: &BasicCommand{fs: flag.NewFlagSet("star", flag.PanicOnError), repository: repository}, starred: true}
+}
+
+// NewUnstarCommand removes star from tasks
+func NewUnstarCommand(repository *task2.Repository) *StarCommand {
+	return &StarCommand{BasicCommand: &BasicCommand{fs: flag.NewFlagSet("unstar", flag.PanicOnError), repository: repository}}
+}
+
+func (s *StarCommand) Init(args []string) error {
+	return s.parse(args, "StarCommand")
+}
+
+func (s *StarCommand) Run() error {
+	results, err := s.repository.SetStarred(s.taskIds, s.starred)
+	if err != nil {
+		return err
+	}
+	if s.starred {
+		return report(results, "Starred")
+	}
+	return report(results, "Unstarred")
+}
+
+func (s *StarCommand) Name() string {
+	return s.fs.Name()
+}
+
+type ConvertCommand struct {
+	*BasicCommand
+	note bool
+}
+
+// NewToNoteCommand converts tasks into notes
+func NewToNoteCommand(repository *task2.Repository) *ConvertCommand {
+	return &ConvertCommand{BasicCommand: &BasicCommand{fs: flag.NewFlagSet("tonote", flag.PanicOnError), repository: repository}, note: true}
+}
+
+// NewToTaskCommand converts notes into tasks
+func NewToTaskCommand(repository *task2.Repository) *ConvertCommand {
+	return &ConvertCommand{BasicCommand: &BasicCommand{fs: flag.NewFlagSet("totask", flag.PanicOnError), repository: repository}}
+}
+
+func (c *ConvertCommand) Init(args []string) error {
+	return c.parse(args, "ConvertCommand")
+}
+
+func (c *ConvertCommand) Run() error {
+	results, err := c.repository.SetNote(c.taskIds, c.note)
+	if err != nil {
+		return err
+	}
+	if c.note {
+		return reportAs(results, "Converted to note")
+	}
+	return reportAs(results, "Converted to task")
+}
+
+func (c *ConvertCommand) Name() string {
+	return c.fs.Name()
+}
+
+type EditCommand struct {
+	fs         *flag.FlagSet
+	repository *task2.Repository
+	id         int
+	boards     string
+	priority   int
+	dueInput   string
+	repeat     string
+	estimate   string
+	editor     bool
+	edit       task2.Edit
+}
+
+// NewEditCommand changes description, boards, priority or due date of existing task
+func NewEditCommand(repository *task2.Repository) *EditCommand {
+	ec := &EditCommand{fs: flag.NewFlagSet("edit", flag.PanicOnError), repository: repository}
+	ec.fs.StringVar(&ec.boards, "b", "", "Comma separated boards replacing current ones")
+	ec.fs.IntVar(&ec.priority, "p", 0, "Task priority 1-3")
+	ec.fs.StringVar(&ec.dueInput, "due", "", "Due date, e.g. 2026-11-01, tomorrow, fri, 3d, none clears it")
+	ec.fs.StringVar(&ec.repeat, "repeat", "", "Recurrence, e.g. daily, weekdays, \"weekly mon,thu\", monthly, \"every 3d\", none clears it")
+	ec.fs.StringVar(&ec.estimate, "est", "", "Estimated effort, e.g. 90m, 2h or 1h30m, none clears it")
+	ec.fs.BoolVar(&ec.editor, "e", false, "Edit description in $EDITOR")
+	return ec
+}
+
+func (e *EditCommand) Init(args []string) error {
+	words, err := parseInterspersed(e.fs, args)
+	if err != nil {
+		return errors.WithMessagef(err, "%s: Failed parse ", e.Name())
+	}
+	if len(words) < 1 {
+		return fmt.Errorf("EditCommand: Missing task id")
+	}
+	id, err := strconv.Atoi(words[0])
+	if err != nil {
+		return fmt.Errorf("EditCommand: Task id should be integer value, provided: %v", words[0])
+	}
+	e.id = id
+
+	e.edit = task2.Edit{}
+	if len(words) > 1 {
+		if e.editor {
+			return fmt.Errorf("EditCommand: Description can not be given together with -e")
+		}
+		body, priority, err := task2.ExtractPriority(strings.Join(words[1:], " "))
+		if err != nil {
+			return errors.WithMessage(err, "EditCommand")
+		}
+		body, e.edit.AddTags, e.edit.AddContexts = task2.ExtractTags(body)
+		if body != "" {
+			e.edit.Description = &body
+		}
+		if priority > 0 && e.priority == 0 {
+			e.priority = priority
+		}
+	}
+	if e.boards != "" {
+		e.edit.Boards = strings.Split(e.boards, ",")
+	}
+	if e.priority != 0 {
+		if err := task2.ValidatePriority(e.priority); err != nil {
+			return errors.WithMessage(err, "EditCommand")
+		}
+		e.edit.Priority = &e.priority
+	}
+	if e.dueInput != "" {
+		var due time.Time
+		if e.dueInput != "none" {
+			if due, err = task2.ParseDue(e.dueInput, time.Now()); err != nil {
+				return errors.WithMessage(err, "EditCommand")
+			}
+		}
+		e.edit.DueDate = &due
+	}
+	if e.repeat != "" {
+		var recurrence task2.Recurrence
+		if e.repeat != "none" {
+			if recurrence, err = task2.ParseRecurrence(e.repeat); err != nil {
+				return errors.WithMessage(err, "EditCommand")
+			}
+		}
+		e.edit.Recurrence = &recurrence
+	}
+	if e.estimate != "" {
+		var estimate time.Duration
+		if e.estimate != "none" {
+			if estimate, err = task2.ParseEstimate(e.estimate); err != nil {
+				return errors.WithMessage(err, "EditCommand")
+			}
+		}
+		e.edit.Estimate = &estimate
+	}
+	if e.edit.IsEmpty() && !e.editor {
+		return fmt.Errorf("EditCommand: Nothing to change, provide description or flags")
+	}
+	return nil
+}
+
+func (e *EditCommand) Run() error {
+	if e.editor {
+		current, err := e.repository.Get(e.id)
+		if err != nil {
+			return err
+		}
+		description, err := editInEditor(current.Description)
+		if err != nil {
+			return errors.WithMessage(err, "EditCommand")
+		}
+		e.edit.Description = &description
+	}
+	edited, err := e.repository.Edit(e.id, e.edit)
+	if err != nil {
+		return err
+	}
+	fmt.Printf("Updated task: %d, %s\n", edited.Id, edited.Description)
+	return nil
+}
+
+func (e *EditCommand) Name() string {
+	return e.fs.Name()
+}
+
+// editInEditor opens text in $EDITOR using temporary file and returns edited text
+func editInEditor(text string) (string, error) {
+	editor := strings.Fields(os.Getenv("EDITOR"))
+	if len(editor) == 0 {
+		editor = []string{"vi"}
+	}
+	file, err := ioutil.TempFile("", "taskl-*.txt")
+	if err != nil {
+		return "", err
+	}
+	defer os.Remove(file.Name())
+	if _, err := file.WriteString(text + "\n"); err != nil {
+		file.Close()
+		return "", err
+	}
+	if err := file.Close(); err != nil {
+		return "", err
+	}
+
+	cmd := exec.Command(editor[0], append(editor[1:], file.Name())...)
+	cmd.Stdin, cmd.Stdout, cmd.Stderr = os.Stdin, os.Stdout, os.Stderr
+	if err := cmd.Run(); err != nil {
+		return "", errors.WithMessagef(err, "Editor: %s failed", editor[0])
+	}
+	data, err := ioutil.ReadFile(file.Name())
+	if err != nil {
+		return "", err
+	}
+	return strings.Join(strings.Fields(string(data)), " "), nil
+}
+
+type MoveCommand struct {
+	*BasicCommand
+	target string
+	copy   bool
+}
+
+// NewMoveCommand moves tasks to other board, with -b only given board is replaced
+func NewMoveCommand(repository *task2.Repository) *MoveCommand {
+	c := &MoveCommand{BasicCommand: &BasicCommand{fs: flag.NewFlagSet("move", flag.PanicOnError), repository: repository}}
+	c.fs.StringVar(&c.board, "b", "", "Board tasks are moved from, other boards are kept")
+	return c
+}
+
+// NewCopyCommand attaches tasks to another board keeping current ones
+func NewCopyCommand(repository *task2.Repository) *MoveCommand {
+	return &MoveCommand{BasicCommand: &BasicCommand{fs: flag.NewFlagSet("copy", flag.PanicOnError), repository: repository}, copy: true}
+}
+
+func (m *MoveCommand) Init(args []string) error {
+	if err := m.fs.Parse(args); err != nil {
+		return errors.WithMessagef(err, "%s: Failed parse ", m.Name())
+	}
+	if m.fs.NArg() < 2 {
+		return fmt.Errorf("MoveCommand: Expected task ids and board")
+	}
+	m.target = m.fs.Arg(m.fs.NArg() - 1)
+	ids, err := parseIds(m.fs.Args()[:m.fs.NArg()-1])
+	if err != nil {
+		return errors.WithMessage(err, "MoveCommand")
+	}
+	m.taskIds = ids
+	return nil
+}
+
+func (m *MoveCommand) Run() error {
+	if m.copy {
+		results, err := m.repository.CopyAll(m.taskIds, m.target)
+		if err != nil {
+			return err
+		}
+		return report(results, "Copied to "+m.target)
+	}
+	results, err := m.repository.MoveAll(m.taskIds, m.target, m.board)
+	if err != nil {
+		return err
+	}
+	return report(results, "Moved to "+m.target)
+}
+
+func (m *MoveCommand) Name() string {
+	return m.fs.Name()
+}
+
+type BoardCommand struct {
+	fs         *flag.FlagSet
+	repository *task2.Repository
+	old        string
+	new        string
+}
+
+// NewBoardCommand manages boards, supported: board rename Old New
+func NewBoardCommand(repository *task2.Repository) *BoardCommand {
+	return &BoardCommand{fs: flag.NewFlagSet("board", flag.PanicOnError), repository: repository}
+}
+
+func (b *BoardCommand) Init(args []string) error {
+	if err := b.fs.Parse(args); err != nil {
+		return errors.WithMessagef(err, "%s: Failed parse ", b.Name())
+	}
+	if b.fs.NArg() < 1 || b.fs.Arg(0) != "rename" {
+		return fmt.Errorf("BoardCommand: Unsupported action, expected: board rename Old New")
+	}
+	if b.fs.NArg() != 3 {
+		return fmt.Errorf("BoardCommand: Expected old and new board name")
+	}
+	b.old, b.new = b.fs.Arg(1), b.fs.Arg(2)
+	return nil
+}
+
+func (b *BoardCommand) Run() error {
+	updated, err := b.repository.RenameBoard(b.old, b.new)
+	if err != nil {
+		return errors.WithMessage(err, "BoardCommand")
+	}
+	fmt.Printf("Renamed board: %s to %s, updated %d tasks\n", b.old, b.new, updated)
+	return nil
+}
+
+func (b *BoardCommand) Name() string {
+	return b.fs.Name()
+}
+
+type FindCommand struct {
+	fs         *flag.FlagSet
+	repository *task2.Repository
+	regex      bool
+	statuses   string
+	board      string
+	since      string
+	until      string
+	priority   int
+	filter     task2.Filter
+}
+
+// NewFindCommand searches task descriptions, terms are case insensitive and all of them have to match
+func NewFindCommand(repository *task2.Repository) *FindCommand {
+	fc := &FindCommand{fs: flag.NewFlagSet("find", flag.PanicOnError), repository: repository}
+	fc.fs.BoolVar(&fc.regex, "regex", false, "Terms are regular expressions")
+	fc.fs.StringVar(&fc.statuses, "status", "", "Comma separated statuses, e.g. pending,in-progress")
+	fc.fs.StringVar(&fc.board, "b", "", "Board name")
+	fc.fs.StringVar(&fc.since, "since", "", "Created on or after date, e.g. 2026-10-01, yesterday, 7d")
+	fc.fs.StringVar(&fc.until, "until", "", "Created on or before date, e.g. 2026-10-01, yesterday, 7d")
+	fc.fs.IntVar(&fc.priority, "p", 0, "Minimal priority 1-3")
+	return fc
+}
+
+func (f *FindCommand) Init(args []string) error {
+	if err := f.fs.Parse(args); err != nil {
+		return errors.WithMessagef(err, "%s: Failed parse ", f.Name())
+	}
+	if f.fs.NArg() < 1 {
+		return fmt.Errorf("FindCommand: Missing search terms")
+	}
+	matcher, err := task2.NewMatcher(f.fs.Args(), f.regex)
+	if err != nil {
+		return errors.WithMessage(err, "FindCommand")
+	}
+	f.filter = task2.Filter{Matcher: matcher, Board: f.board}
+	if f.statuses != "" {
+		for _, name := range strings.Split(f.statuses, ",") {
+			status, err := task2.ParseStatus(name)
+			if err != nil {
+				return errors.WithMessage(err, "FindCommand")
+			}
+			f.filter.Statuses = append(f.filter.Statuses, status)
+		}
+	}
+	now := time.Now()
+	if f.since != "" {
+		if f.filter.CreatedAfter, err = task2.ParseDate(f.since, now); err != nil {
+			return errors.WithMessage(err, "FindCommand")
+		}
+	}
+	if f.until != "" {
+		until, err := task2.ParseDate(f.until, now)
+		if err != nil {
+			return errors.WithMessage(err, "FindCommand")
+		}
+		f.filter.CreatedBefore = until.AddDate(0, 0, 1)
+	}
+	if f.priority != 0 {
+		if err := task2.ValidatePriority(f.priority); err != nil {
+			return errors.WithMessage(err, "FindCommand")
+		}
+		f.filter.MinPriority = f.priority
+	}
+	return nil
+}
+
+func (f *FindCommand) Run() error {
+	found, err := f.repository.Find(f.filter)
+	if err != nil {
+		return errors.WithMessagef(err, "%s: Failed to fetch Tasks ", f.Name())
+	}
+	if len(found.Tasks) == 0 {
+		fmt.Println("No matching tasks")
+		return nil
+	}
+	summary, err := calculateSummary(&found)
+	if err != nil {
+		return err
+	}
+	if isTerminal(os.Stdout) {
+		summary.Highlight = func(text string) string {
+			return f.filter.Matcher.Highlight(text, highlight)
+		}
+	}
+	return renderOutput(os.Stdout, summary)
+}
+
+func (f *FindCommand) Name() string {
+	return f.fs.Name()
+}
+
+// highlight marks text as bold yellow on ansi terminals
+func highlight(text string) string {
+	return "\x1b[1;33m" + text + "\x1b[0m"
+}
+
+func isTerminal(file *os.File) bool {
+	info, err := file.Stat()
+	return err == nil && info.Mode()&os.ModeCharDevice != 0
+}
+
+type QueryCommand struct {
+	fs         *flag.FlagSet
+	repository *task2.Repository
+	local      bool
+	query      *task2.Query
+}
+
+// NewQueryCommand lists tasks matching query expression, e.g.
+// board:Work status:pending priority>=2 created<7d "deploy" sort:-priority limit:10
+func NewQueryCommand(repository *task2.Repository) *QueryCommand {
+	qc := &QueryCommand{fs: flag.NewFlagSet("query", flag.PanicOnError), repository: repository}
+	qc.fs.BoolVar(&qc.local, "local", false, "Show board local task numbers instead of ids")
+	return qc
+}
+
+func (q *QueryCommand) Init(args []string) error {
+	if err := q.fs.Parse(args); err != nil {
+		return errors.WithMessagef(err, "%s: Failed parse ", q.Name())
+	}
+	query, err := task2.ParseQuery(task2.JoinArgs(q.fs.Args()), time.Now())
+	if err != nil {
+		return errors.WithMessage(err, "QueryCommand")
+	}
+	q.query = query
+	return nil
+}
+
+func (q *QueryCommand) Run() error {
+	found, err := q.repository.Query(q.query)
+	if err != nil {
+		return errors.WithMessagef(err, "%s: Failed to fetch Tasks ", q.Name())
+	}
+	if len(found.Tasks) == 0 {
+		fmt.Println("No matching tasks")
+		return nil
+	}
+	now := time.Now()
+	var summary TaskSummary
+	if len(q.query.Sort) > 0 {
+		// keep order requested in query
+		summary = groupTasks(&found, now)
+	} else if summary, err = calculateSummaryAt(&found, now); err != nil {
+		return err
+	}
+	summary.LocalNumbers = q.local
+	if q.local {
+		all, err := q.repository.GetAll()
+		if err != nil {
+			return errors.WithMessagef(err, "%s: Failed to fetch Tasks ", q.Name())
+		}
+		summary.numberLocally(*all)
+	}
+	return renderOutput(os.Stdout, summary)
+}
+
+func (q *QueryCommand) Name() string {
+	return q.fs.Name()
+}
+
+type ClearCommand struct {
+	fs         *flag.FlagSet
+	repository *task2.Repository
+	board      string
+}
+
+// NewClearCommand moves done and cancelled tasks into archive
+func NewClearCommand(repository *task2.Repository) *ClearCommand {
+	cc := &ClearCommand{fs: flag.NewFlagSet("clear", flag.PanicOnError), repository: repository}
+	cc.fs.StringVar(&cc.board, "b", "", "Clear only given board")
+	return cc
+}
+
+func (c *ClearCommand) Init(args []string) error {
+	if err := c.fs.Parse(args); err != nil {
+		return errors.WithMessagef(err, "%s: Failed parse ", c.Name())
+	}
+	return nil
+}
+
+func (c *ClearCommand) Run() error {
+	results, err := c.repository.Clear(c.board)
+	if err != nil {
+		return err
+	}
+	if len(results) == 0 {
+		fmt.Println("No finished tasks to clear")
+		return nil
+	}
+	return report(results, "Archived")
+}
+
+func (c *ClearCommand) Name() string {
+	return c.fs.Name()
+}
+
+type ArchiveCommand struct {
+	fs         *flag.FlagSet
+	repository *task2.Repository
+}
+
+// NewArchiveCommand lists archived tasks
+func NewArchiveCommand(repository *task2.Repository) *ArchiveCommand {
+	return &ArchiveCommand{fs: flag.NewFlagSet("archive", flag.PanicOnError), repository: repository}
+}
+
+func (a *ArchiveCommand) Init(args []string) error {
+	if err := a.fs.Parse(args); err != nil {
+		return errors.WithMessagef(err, "%s: Failed parse ", a.Name())
+	}
+	return nil
+}
+
+func (a *ArchiveCommand) Run() error {
+	tl, err := a.repository.Archived()
+	if err != nil {
+		return errors.WithMessagef(err, "%s: Failed to fetch Tasks ", a.Name())
+	}
+	if len(tl.Tasks) == 0 {
+		fmt.Println("Archive is empty")
+		return nil
+	}
+	summary, err := calculateSummary(tl)
+	if err != nil {
+		return err
+	}
+	return renderOutput(os.Stdout, summary)
+}
+
+func (a *ArchiveCommand) Name() string {
+	return a.fs.Name()
+}
+
+type RestoreCommand struct {
+	*BasicCommand
+}
+
+// NewRestoreCommand brings archived tasks back under their original ids
+func NewRestoreCommand(repository *task2.Repository) *RestoreCommand {
+	return &RestoreCommand{&BasicCommand{fs: flag.NewFlagSet("restore", flag.PanicOnError), repository: repository}}
+}
+
+func (r *RestoreCommand) Init(args []string) error {
+	return r.parse(args, "RestoreCommand")
+}
+
+func (r *RestoreCommand) Run() error {
+	results, err := r.repository.RestoreAll(r.taskIds)
+	if err != nil {
+		return err
+	}
+	return report(results, "Restored")
+}
+
+func (r *RestoreCommand) Name() string {
+	return r.fs.Name()
+}
+
+type UndoCommand struct {
+	fs         *flag.FlagSet
+	repository *task2.Repository
+	redo       bool
+}
+
+// NewUndoCommand reverts last operation recorded in journal
+func NewUndoCommand(repository *task2.Repository) *UndoCommand {
+	return &UndoCommand{fs: flag.NewFlagSet("undo", flag.PanicOnError), repository: repository}
+}
+
+// NewRedoCommand applies again last undone operation
+func NewRedoCommand(repository *task2.Repository) *UndoCommand {
+	return &UndoCommand{fs: flag.NewFlagSet("redo", flag.PanicOnError), repository: repository, redo: true}
+}
+
+func (u *UndoCommand) Init(args []string) error {
+	if err := u.fs.Parse(args); err != nil {
+		return errors.WithMessagef(err, "%s: Failed parse ", u.Name())
+	}
+	return nil
+}
+
+func (u *UndoCommand) Run() error {
+	if u.redo {
+		entry, err := u.repository.Redo()
+		if err != nil {
+			return errors.WithMessage(err, "RedoCommand")
+		}
+		fmt.Printf("Redone: %s\n", entry)
+		return nil
+	}
+	entry, err := u.repository.Undo()
+	if err != nil {
+		return errors.WithMessage(err, "UndoCommand")
+	}
+	fmt.Printf("Undone: %s\n", entry)
+	return nil
+}
+
+func (u *UndoCommand) Name() string {
+	return u.fs.Name()
+}
+
+type HistoryCommand struct {
+	*BasicCommand
+}
+
+// NewHistoryCommand prints audit log of tasks, archived tasks included
+func NewHistoryCommand(repository *task2.Repository) *HistoryCommand {
+	return &HistoryCommand{&BasicCommand{fs: flag.NewFlagSet("history", flag.PanicOnError), repository: repository}}
+}
+
+func (h *HistoryCommand) Init(args []string) error {
+	return h.parse(args, "HistoryCommand")
+}
+
+func (h *HistoryCommand) Run() error {
+	for _, id := range h.taskIds {
+		t, err := h.repository.History(id)
+		if err != nil {
+			return err
+		}
+		renderHistory(os.Stdout, *t)
+	}
+	return nil
+}
+
+func (h *HistoryCommand) Name() string {
+	return h.fs.Name()
+}
+
+type TagsCommand struct {
+	fs         *flag.FlagSet
+	repository *task2.Repository
+}
+
+// NewTagsCommand lists every tag and context with task counts
+func NewTagsCommand(repository *task2.Repository) *TagsCommand {
+	return &TagsCommand{fs: flag.NewFlagSet("tags", flag.PanicOnError), repository: repository}
+}
+
+func (tc *TagsCommand) Init(args []string) error {
+	if err := tc.fs.Parse(args); err != nil {
+		return errors.WithMessagef(err, "%s: Failed parse ", tc.Name())
+	}
+	return nil
+}
+
+func (tc *TagsCommand) Run() error {
+	tl, err := tc.repository.GetAll()
+	if err != nil {
+		return errors.WithMessagef(err, "%s: Failed to fetch Tasks ", tc.Name())
+	}
+	labels := calculateLabelSummary(tl, time.Now())
+	if len(labels) == 0 {
+		fmt.Println("No tags or contexts")
+		return nil
+	}
+	return renderLabels(os.Stdout, labels)
+}
+
+func (tc *TagsCommand) Name() string {
+	return tc.fs.Name()
+}
+
+type DependsCommand struct {
+	*BasicCommand
+	id     int
+	remove bool
+}
+
+// NewDependsCommand declares that task waits for other tasks, e.g. depends 7 4
+func NewDependsCommand(repository *task2.Repository) *DependsCommand {
+	c := &DependsCommand{BasicCommand: &BasicCommand{fs: flag.NewFlagSet("depends", flag.PanicOnError), repository: repository}}
+	c.fs.BoolVar(&c.remove, "r", false, "Remove given prerequisites instead of adding them")
+	return c
+}
+
+func (d *DependsCommand) Init(args []string) error {
+	if err := d.fs.Parse(args); err != nil {
+		return errors.WithMessagef(err, "%s: Failed parse ", d.Name())
+	}
+	if d.fs.NArg() < 2 {
+		return fmt.Errorf("DependsCommand: Expected task id and prerequisite ids")
+	}
+	id, err := strconv.Atoi(d.fs.Arg(0))
+	if err != nil {
+		return fmt.Errorf("DependsCommand: Task id should be integer value, provided: %v", d.fs.Arg(0))
+	}
+	d.id = id
+	ids, err := parseIds(d.fs.Args()[1:])
+	if err != nil {
+		return errors.WithMessage(err, "DependsCommand")
+	}
+	d.taskIds = ids
+	return nil
+}
+
+func (d *DependsCommand) Run() error {
+	if d.remove {
+		updated, err := d.repository.RemoveDependencies(d.id, d.taskIds)
+		if err != nil {
+			return err
+		}
+		fmt.Printf("Task %d depends on: %s\n", updated.Id, formatIds(updated.DependsOn))
+		return nil
+	}
+	updated, err := d.repository.AddDependencies(d.id, d.taskIds)
+	if err != nil {
+		return err
+	}
+	fmt.Printf("Task %d depends on: %s\n", updated.Id, formatIds(updated.DependsOn))
+	return nil
+}
+
+func (d *DependsCommand) Name() string {
+	return d.fs.Name()
+}
+
+type NextCommand struct {
+	fs         *flag.FlagSet
+	repository *task2.Repository
+	board      string
+}
+
+// NewNextCommand lists open tasks whose prerequisites are all finished
+func NewNextCommand(repository *task2.Repository) *NextCommand {
+	nc := &NextCommand{fs: flag.NewFlagSet("next", flag.PanicOnError), repository: repository}
+	nc.fs.StringVar(&nc.board, "b", "", "Show only tasks of given board")
+	return nc
+}
+
+func (nc *NextCommand) Init(args []string) error {
+	if err := nc.fs.Parse(args); err != nil {
+		return errors.WithMessagef(err, "%s: Failed parse ", nc.Name())
+	}
+	return nil
+}
+
+func (nc *NextCommand) Run() error {
+	tl, err := nc.repository.GetAll()
+	if err != nil {
+		return errors.WithMessagef(err, "%s: Failed to fetch Tasks ", nc.Name())
+	}
+	ready := tl.Ready()
+	if nc.board != "" {
+		ready = ready.Filter(func(t task2.Task) bool { return t.OnBoard(nc.board) })
+	}
+	if len(ready.Tasks) == 0 {
+		fmt.Println("No tasks ready to start")
+		return nil
+	}
+	summary, err := calculateSummary(&ready)
+	if err != nil {
+		return err
+	}
+	return renderOutput(os.Stdout, summary)
+}
+
+func (nc *NextCommand) Name() string {
+	return nc.fs.Name()
+}
+
+// formatIds prints ids as comma separated list, none when empty
+func formatIds(ids []int) string {
+	if len(ids) == 0 {
+		return "none"
+	}
+	parts := make([]string, len(ids))
+	for idx, id := range ids {
+		parts[idx] = strconv.Itoa(id)
+	}
+	return strings.Join(parts, ", ")
+}
+
+type EstimatesCommand struct {
+	fs         *flag.FlagSet
+	repository *task2.Repository
+	board      string
+}
+
+// NewEstimatesCommand compares estimated and tracked time of completed tasks, archived ones included
+func NewEstimatesCommand(repository *task2.Repository) *EstimatesCommand {
+	ec := &EstimatesCommand{fs: flag.NewFlagSet("estimates", flag.PanicOnError), repository: repository}
+	ec.fs.StringVar(&ec.board, "b", "", "Show only given board")
+	return ec
+}
+
+func (ec *EstimatesCommand) Init(args []string) error {
+	if err := ec.fs.Parse(args); err != nil {
+		return errors.WithMessagef(err, "%s: Failed parse ", ec.Name())
+	}
+	return nil
+}
+
+func (ec *EstimatesCommand) Run() error {
+	tl, err := ec.repository.GetAll()
+	if err != nil {
+		return errors.WithMessagef(err, "%s: Failed to fetch Tasks ", ec.Name())
+	}
+	archived, err := ec.repository.Archived()
+	switch {
+	case err == nil:
+		tl.Tasks = append(tl.Tasks, archived.Tasks...)
+	case !errors.Is(err, task2.ErrNoArchive):
+		return errors.WithMessagef(err, "%s: Failed to fetch archived Tasks ", ec.Name())
+	}
+	if ec.board != "" {
+		filtered := tl.Filter(func(t task2.Task) bool { return t.OnBoard(ec.board) })
+		tl = &filtered
+	}
+	estimates := calculateEstimates(tl)
+	if len(estimates) == 0 {
+		fmt.Println("No completed tasks with estimate and tracked time")
+		return nil
+	}
+	return renderEstimates(os.Stdout, estimates)
+}
+
+func (ec *EstimatesCommand) Name() string {
+	return ec.fs.Name()
 }
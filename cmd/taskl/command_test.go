@@ -0,0 +1,552 @@
+package main
+
+import (
+	"github.com/stretchr/testify/assert"
+	"github.com/wprzechrzta/taskl/cmd/taskl/task"
+	"io/ioutil"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestRunCommand_CreateAndComplete(t *testing.T) {
+	assert := assert.New(t)
+	repository := newTestRepository()
+
+	assert.NoError(runCommand([]string{"t", "-b", "Work", "Write report"}, repository, AppConfig{}))
+	assert.NoError(runCommand([]string{"c", "1"}, repository, AppConfig{}))
+
+	loaded, err := repository.Get(1)
+	assert.NoError(err)
+	assert.Equal("Write report", loaded.Description)
+	assert.Equal([]string{"Work"}, loaded.Boards)
+	assert.Equal(task.StatusDone, loaded.Status)
+}
+
+func TestRunCommand_UnknownSubcommand(t *testing.T) {
+	repository := newTestRepository()
+	assert.Error(t, runCommand([]string{"nope"}, repository, AppConfig{}))
+}
+
+// newTestRepository creates repository keeping given tasks in memory
+func newTestRepository(tasks ...task.Task) *task.Repository {
+	return task.NewRepositoryWithStore(task.NewMemoryStore(tasks...))
+}
+
+// storedTasks returns tasks of repository by id
+func storedTasks(t *testing.T, repository *task.Repository) map[int]task.Task {
+	all, err := repository.GetAll()
+	assert.NoError(t, err)
+	tasks := map[int]task.Task{}
+	for _, stored := range all.Tasks {
+		tasks[stored.Id] = stored
+	}
+	return tasks
+}
+
+func TestRunCommand_BoardFlagVerifiesMembership(t *testing.T) {
+	assert := assert.New(t)
+	repository := newTestRepository(
+		task.Task{Id: 1, Description: "Report", Boards: []string{"Work"}, Status: task.StatusPending},
+		task.Task{Id: 2, Description: "Milk", Boards: []string{"Home"}, Status: task.StatusPending},
+		task.Task{Id: 3, Description: "Review", Boards: []string{"Work"}, Status: task.StatusCancelled},
+		task.Task{Id: 4, Description: "Deploy", Boards: []string{"Work"}, Status: task.StatusPending},
+	)
+
+	err := runCommand([]string{"c", "-b", "Home", "1"}, repository, AppConfig{})
+	assert.EqualError(err, "1 of 1 tasks failed")
+	loaded, err := repository.Get(1)
+	assert.NoError(err)
+	assert.Equal(task.StatusPending, loaded.Status)
+
+	assert.NoError(runCommand([]string{"c", "-b", "Work", "1"}, repository, AppConfig{}))
+	loaded, err = repository.Get(1)
+	assert.NoError(err)
+	assert.Equal(task.StatusDone, loaded.Status)
+}
+
+func TestRunCommand_BoardLocalNumber(t *testing.T) {
+	assert := assert.New(t)
+	repository := newTestRepository(
+		task.Task{Id: 1, Description: "Report", Boards: []string{"Work"}, Status: task.StatusPending},
+		task.Task{Id: 2, Description: "Milk", Boards: []string{"Home"}, Status: task.StatusPending},
+		task.Task{Id: 3, Description: "Review", Boards: []string{"Work"}, Status: task.StatusCancelled},
+		task.Task{Id: 4, Description: "Deploy", Boards: []string{"Work"}, Status: task.StatusPending},
+	)
+
+	//third task on Work board is task 4
+	assert.NoError(runCommand([]string{"b", "-b", "Work", "-local", "3"}, repository, AppConfig{}))
+	loaded, err := repository.Get(4)
+	assert.NoError(err)
+	assert.Equal(task.StatusInProgress, loaded.Status)
+
+	assert.Error(runCommand([]string{"b", "-b", "Work", "-local", "4"}, repository, AppConfig{}))
+	assert.Error(runCommand([]string{"b", "-local", "1"}, repository, AppConfig{}))
+}
+
+func TestRunCommand_CompleteWholeBoard(t *testing.T) {
+	assert := assert.New(t)
+	repository := newTestRepository(
+		task.Task{Id: 1, Description: "Report", Boards: []string{"Work"}, Status: task.StatusPending},
+		task.Task{Id: 2, Description: "Milk", Boards: []string{"Home"}, Status: task.StatusPending},
+		task.Task{Id: 3, Description: "Review", Boards: []string{"Work"}, Status: task.StatusCancelled},
+		task.Task{Id: 4, Description: "Deploy", Boards: []string{"Work"}, Status: task.StatusPending},
+	)
+
+	assert.NoError(runCommand([]string{"c", "-b", "Work", "-all"}, repository, AppConfig{}))
+
+	tasks := storedTasks(t, repository)
+	assert.Equal(task.StatusDone, tasks[1].Status)
+	assert.Equal(task.StatusPending, tasks[2].Status)
+	assert.Equal(task.StatusCancelled, tasks[3].Status)
+	assert.Equal(task.StatusDone, tasks[4].Status)
+}
+
+func TestParseIds(t *testing.T) {
+	assert := assert.New(t)
+
+	ids, err := parseIds([]string{"3", "5", "7-10", "5", "9-11"})
+	assert.NoError(err)
+	assert.Equal([]int{3, 5, 7, 8, 9, 10, 11}, ids)
+
+	_, err = parseIds([]string{"12-7"})
+	assert.Error(err)
+	_, err = parseIds([]string{"x"})
+	assert.Error(err)
+	_, err = parseIds([]string{"3-"})
+	assert.Error(err)
+	_, err = parseIds([]string{"1-1000000000"})
+	assert.Error(err)
+
+	ids, err = parseIds([]string{"1-10000"})
+	assert.NoError(err)
+	assert.Equal(10000, len(ids))
+}
+
+func TestRunCommand_BatchReportsFailures(t *testing.T) {
+	assert := assert.New(t)
+	repository := newTestRepository(
+		task.Task{Id: 1, Description: "Report", Boards: []string{"Work"}, Status: task.StatusPending},
+		task.Task{Id: 2, Description: "Milk", Boards: []string{"Home"}, Status: task.StatusPending},
+		task.Task{Id: 3, Description: "Review", Boards: []string{"Work"}, Status: task.StatusCancelled},
+		task.Task{Id: 4, Description: "Deploy", Boards: []string{"Work"}, Status: task.StatusPending},
+	)
+
+	//task 3 is cancelled and can not be completed, task 9 does not exist
+	err := runCommand([]string{"c", "1-4", "9"}, repository, AppConfig{})
+	assert.EqualError(err, "2 of 5 tasks failed")
+
+	tasks := storedTasks(t, repository)
+	assert.Equal(task.StatusDone, tasks[1].Status)
+	assert.Equal(task.StatusDone, tasks[2].Status)
+	assert.Equal(task.StatusCancelled, tasks[3].Status)
+	assert.Equal(task.StatusDone, tasks[4].Status)
+
+	assert.NoError(runCommand([]string{"d", "-purge", "1", "2"}, repository, AppConfig{}))
+	assert.Equal(2, len(storedTasks(t, repository)))
+}
+
+func TestRunCommand_CreateWithPriority(t *testing.T) {
+	assert := assert.New(t)
+	repository := newTestRepository()
+
+	assert.NoError(runCommand([]string{"t", "Deploy", "p:3", "hotfix"}, repository, AppConfig{}))
+	assert.NoError(runCommand([]string{"t", "-p", "2", "Write docs"}, repository, AppConfig{}))
+	assert.Error(runCommand([]string{"t", "-p", "5", "Too much"}, repository, AppConfig{}))
+	assert.NoError(runCommand([]string{"priority", "2", "1"}, repository, AppConfig{}))
+
+	first, err := repository.Get(1)
+	assert.NoError(err)
+	assert.Equal("Deploy hotfix", first.Description)
+	assert.Equal(task.PriorityHigh, first.Priority)
+	second, err := repository.Get(2)
+	assert.NoError(err)
+	assert.Equal(task.PriorityNormal, second.Priority)
+}
+
+func TestRunCommand_StarAndUnstar(t *testing.T) {
+	assert := assert.New(t)
+	repository := newTestRepository(
+		task.Task{Id: 1, Description: "Report", Boards: []string{"Work"}, Status: task.StatusPending},
+		task.Task{Id: 2, Description: "Milk", Boards: []string{"Home"}, Status: task.StatusPending},
+		task.Task{Id: 3, Description: "Review", Boards: []string{"Work"}, Status: task.StatusCancelled},
+		task.Task{Id: 4, Description: "Deploy", Boards: []string{"Work"}, Status: task.StatusPending},
+	)
+
+	assert.NoError(runCommand([]string{"star", "1", "2", "4"}, repository, AppConfig{}))
+	assert.NoError(runCommand([]string{"unstar", "2"}, repository, AppConfig{}))
+	assert.NoError(runCommand([]string{"listall", "-starred"}, repository, AppConfig{}))
+
+	all, err := repository.GetAll()
+	assert.NoError(err)
+	starred := all.Filter(func(t task.Task) bool { return t.IsStarred })
+	var ids []int
+	for _, t := range starred.Tasks {
+		ids = append(ids, t.Id)
+	}
+	assert.Equal([]int{1, 4}, ids)
+}
+
+func TestRunCommand_NotesAndConversion(t *testing.T) {
+	assert := assert.New(t)
+	repository := newTestRepository()
+
+	assert.NoError(runCommand([]string{"note", "-b", "Work", "Office closed on Friday"}, repository, AppConfig{}))
+	note, err := repository.Get(1)
+	assert.NoError(err)
+	assert.True(note.IsNote)
+	assert.Equal([]string{"Work"}, note.Boards)
+
+	assert.Error(runCommand([]string{"c", "1"}, repository, AppConfig{}))
+	assert.NoError(runCommand([]string{"totask", "1"}, repository, AppConfig{}))
+	assert.NoError(runCommand([]string{"b", "1"}, repository, AppConfig{}))
+	assert.NoError(runCommand([]string{"tonote", "1"}, repository, AppConfig{}))
+
+	note, err = repository.Get(1)
+	assert.NoError(err)
+	assert.True(note.IsNote)
+	assert.Equal(task.StatusPending, note.Status)
+	assert.True(note.StartedAt.IsZero())
+}
+
+func TestRunCommand_CreateWithDue(t *testing.T) {
+	assert := assert.New(t)
+	repository := newTestRepository()
+
+	assert.NoError(runCommand([]string{"t", "--due", "2026-11-01", "Pay taxes"}, repository, AppConfig{}))
+	assert.NoError(runCommand([]string{"t", "--due", "tomorrow", "Call bank"}, repository, AppConfig{}))
+	assert.Error(runCommand([]string{"t", "--due", "someday", "Learn piano"}, repository, AppConfig{}))
+	assert.NoError(runCommand([]string{"listall", "-overdue"}, repository, AppConfig{}))
+	assert.NoError(runCommand([]string{"listall", "-due-within", "2d"}, repository, AppConfig{}))
+	assert.Error(runCommand([]string{"listall", "-due-within", "soon"}, repository, AppConfig{}))
+
+	first, err := repository.Get(1)
+	assert.NoError(err)
+	assert.True(time.Date(2026, 11, 1, 0, 0, 0, 0, time.Local).Equal(first.DueDate))
+	second, err := repository.Get(2)
+	assert.NoError(err)
+	assert.Equal(1, second.DueInDays(time.Now()))
+}
+
+func TestRunCommand_Edit(t *testing.T) {
+	assert := assert.New(t)
+	repository := newTestRepository(
+		task.Task{Id: 1, Description: "Report", Boards: []string{"Work"}, Status: task.StatusPending},
+		task.Task{Id: 2, Description: "Milk", Boards: []string{"Home"}, Status: task.StatusPending},
+		task.Task{Id: 3, Description: "Review", Boards: []string{"Work"}, Status: task.StatusCancelled},
+		task.Task{Id: 4, Description: "Deploy", Boards: []string{"Work"}, Status: task.StatusPending},
+	)
+
+	assert.NoError(runCommand([]string{"edit", "-b", "Home,Errands", "-p", "2", "--due", "2026-11-01", "1", "Renamed", "task"}, repository, AppConfig{}))
+	edited, err := repository.Get(1)
+	assert.NoError(err)
+	assert.Equal(1, edited.Id)
+	assert.Equal("Renamed task", edited.Description)
+	assert.Equal([]string{"Home", "Errands"}, edited.Boards)
+	assert.Equal(task.PriorityMedium, edited.Priority)
+	assert.True(edited.HasDue())
+
+	assert.NoError(runCommand([]string{"edit", "--due", "none", "1"}, repository, AppConfig{}))
+	edited, err = repository.Get(1)
+	assert.NoError(err)
+	assert.False(edited.HasDue())
+	assert.Equal("Renamed task", edited.Description)
+
+	assert.Error(runCommand([]string{"edit", "1"}, repository, AppConfig{}))
+	assert.Error(runCommand([]string{"edit", "9", "Missing"}, repository, AppConfig{}))
+	assert.Error(runCommand([]string{"edit", "-e", "1", "Both"}, repository, AppConfig{}))
+}
+
+func TestRunCommand_EditInEditor(t *testing.T) {
+	assert := assert.New(t)
+	script := filepath.Join(t.TempDir(), "editor.sh")
+	assert.NoError(ioutil.WriteFile(script, []byte("#!/bin/sh\necho 'Written in editor' > \"$1\"\n"), 0755))
+	t.Setenv("EDITOR", script)
+	repository := newTestRepository(
+		task.Task{Id: 1, Description: "Report", Boards: []string{"Work"}, Status: task.StatusPending},
+		task.Task{Id: 2, Description: "Milk", Boards: []string{"Home"}, Status: task.StatusPending},
+		task.Task{Id: 3, Description: "Review", Boards: []string{"Work"}, Status: task.StatusCancelled},
+		task.Task{Id: 4, Description: "Deploy", Boards: []string{"Work"}, Status: task.StatusPending},
+	)
+
+	assert.NoError(runCommand([]string{"edit", "-e", "2"}, repository, AppConfig{}))
+	edited, err := repository.Get(2)
+	assert.NoError(err)
+	assert.Equal("Written in editor", edited.Description)
+}
+
+func TestRunCommand_MoveCopyAndRenameBoard(t *testing.T) {
+	assert := assert.New(t)
+	repository := newTestRepository(
+		task.Task{Id: 1, Description: "Report", Boards: []string{"Work"}, Status: task.StatusPending},
+		task.Task{Id: 2, Description: "Milk", Boards: []string{"Home"}, Status: task.StatusPending},
+		task.Task{Id: 3, Description: "Review", Boards: []string{"Work"}, Status: task.StatusCancelled},
+		task.Task{Id: 4, Description: "Deploy", Boards: []string{"Work"}, Status: task.StatusPending},
+	)
+
+	assert.NoError(runCommand([]string{"copy", "1", "2", "Errands"}, repository, AppConfig{}))
+	assert.NoError(runCommand([]string{"move", "-b", "Errands", "2", "Shopping"}, repository, AppConfig{}))
+	assert.NoError(runCommand([]string{"move", "3-4", "Archive"}, repository, AppConfig{}))
+	assert.NoError(runCommand([]string{"board", "rename", "Work", "Office"}, repository, AppConfig{}))
+	assert.Error(runCommand([]string{"move", "1"}, repository, AppConfig{}))
+	assert.Error(runCommand([]string{"board", "delete", "Work"}, repository, AppConfig{}))
+	assert.Error(runCommand([]string{"board", "rename", "Work", "Office"}, repository, AppConfig{}))
+
+	tasks := storedTasks(t, repository)
+	assert.Equal([]string{"Office", "Errands"}, tasks[1].Boards)
+	assert.Equal([]string{"Home", "Shopping"}, tasks[2].Boards)
+	assert.Equal([]string{"Archive"}, tasks[3].Boards)
+	assert.Equal([]string{"Archive"}, tasks[4].Boards)
+}
+
+func TestRunCommand_Find(t *testing.T) {
+	assert := assert.New(t)
+	repository := newTestRepository(
+		task.Task{Id: 1, Description: "Report", Boards: []string{"Work"}, Status: task.StatusPending},
+		task.Task{Id: 2, Description: "Milk", Boards: []string{"Home"}, Status: task.StatusPending},
+		task.Task{Id: 3, Description: "Review", Boards: []string{"Work"}, Status: task.StatusCancelled},
+		task.Task{Id: 4, Description: "Deploy", Boards: []string{"Work"}, Status: task.StatusPending},
+	)
+
+	assert.NoError(runCommand([]string{"find", "-b", "Work", "-status", "pending,done", "rep"}, repository, AppConfig{}))
+	assert.NoError(runCommand([]string{"find", "-regex", "-since", "7d", "^(Milk|Deploy)$"}, repository, AppConfig{}))
+	assert.NoError(runCommand([]string{"find", "nothing matches"}, repository, AppConfig{}))
+	assert.Error(runCommand([]string{"find"}, repository, AppConfig{}))
+	assert.Error(runCommand([]string{"find", "-status", "unknown", "rep"}, repository, AppConfig{}))
+	assert.Error(runCommand([]string{"find", "-regex", "(rep"}, repository, AppConfig{}))
+	assert.Error(runCommand([]string{"find", "-since", "someday", "rep"}, repository, AppConfig{}))
+}
+
+func TestRunCommand_Query(t *testing.T) {
+	assert := assert.New(t)
+	repository := newTestRepository(
+		task.Task{Id: 1, Description: "Report", Boards: []string{"Work"}, Status: task.StatusPending},
+		task.Task{Id: 2, Description: "Milk", Boards: []string{"Home"}, Status: task.StatusPending},
+		task.Task{Id: 3, Description: "Review", Boards: []string{"Work"}, Status: task.StatusCancelled},
+		task.Task{Id: 4, Description: "Deploy", Boards: []string{"Work"}, Status: task.StatusPending},
+	)
+
+	assert.NoError(runCommand([]string{"query", "board:Work", "status:pending", `"dep"`}, repository, AppConfig{}))
+	assert.NoError(runCommand([]string{"query", "sort:-id", "limit:2"}, repository, AppConfig{}))
+	assert.NoError(runCommand([]string{"query", "-local", "board:Work", "sort:-id"}, repository, AppConfig{}))
+	assert.NoError(runCommand([]string{"query"}, repository, AppConfig{}))
+	assert.Error(runCommand([]string{"query", "colour:red"}, repository, AppConfig{}))
+}
+
+func TestRunCommand_ArchiveAndRestore(t *testing.T) {
+	assert := assert.New(t)
+	archive := task.NewMemoryStore()
+	repository := task.NewRepositoryWithArchive(task.NewMemoryStore(
+		task.Task{Id: 1, Description: "Report", Boards: []string{"Work"}, Status: task.StatusDone},
+		task.Task{Id: 2, Description: "Milk", Boards: []string{"Home"}, Status: task.StatusPending},
+		task.Task{Id: 3, Description: "Review", Boards: []string{"Work"}, Status: task.StatusCancelled},
+	), archive)
+
+	assert.NoError(runCommand([]string{"clear"}, repository, AppConfig{}))
+	assert.NoError(runCommand([]string{"d", "2"}, repository, AppConfig{}))
+	assert.NoError(runCommand([]string{"archive"}, repository, AppConfig{}))
+	all, err := repository.GetAll()
+	assert.NoError(err)
+	assert.Empty(all.Tasks)
+	archived, err := archive.List()
+	assert.NoError(err)
+	assert.Equal(3, len(archived.Tasks))
+
+	assert.NoError(runCommand([]string{"restore", "2", "3"}, repository, AppConfig{}))
+	assert.Error(runCommand([]string{"restore", "2"}, repository, AppConfig{}))
+	restored, err := repository.Get(3)
+	assert.NoError(err)
+	assert.Equal("Review", restored.Description)
+	assert.Equal(task.StatusCancelled, restored.Status)
+}
+
+func TestRunCommand_UndoRedo(t *testing.T) {
+	assert := assert.New(t)
+	repository := task.NewRepositoryWithJournal(task.NewMemoryStore(), task.NewMemoryStore(), task.NewMemoryJournal(10))
+
+	assert.NoError(runCommand([]string{"t", "Report"}, repository, AppConfig{}))
+	assert.NoError(runCommand([]string{"d", "1"}, repository, AppConfig{}))
+	assert.NoError(runCommand([]string{"undo"}, repository, AppConfig{}))
+	restored, err := repository.Get(1)
+	assert.NoError(err)
+	assert.Equal("Report", restored.Description)
+
+	assert.NoError(runCommand([]string{"redo"}, repository, AppConfig{}))
+	_, err = repository.Get(1)
+	assert.ErrorIs(err, task.ErrTaskNotFound)
+	assert.Error(runCommand([]string{"redo"}, repository, AppConfig{}))
+	assert.Error(runCommand([]string{"undo"}, newTestRepository(), AppConfig{}))
+}
+
+func TestRunCommand_History(t *testing.T) {
+	assert := assert.New(t)
+	repository := newTestRepository()
+
+	assert.NoError(runCommand([]string{"t", "Report"}, repository, AppConfig{}))
+	assert.NoError(runCommand([]string{"b", "1"}, repository, AppConfig{}))
+	assert.NoError(runCommand([]string{"cancel", "1"}, repository, AppConfig{}))
+	assert.NoError(runCommand([]string{"history", "1"}, repository, AppConfig{}))
+	assert.Error(runCommand([]string{"history", "2"}, repository, AppConfig{}))
+
+	cancelled, err := repository.Get(1)
+	assert.NoError(err)
+	var types []string
+	for _, e := range cancelled.History {
+		types = append(types, e.Type)
+	}
+	assert.Equal([]string{task.EventCreated, task.EventStarted, task.EventCancelled}, types)
+}
+
+func TestRunCommand_Tags(t *testing.T) {
+	assert := assert.New(t)
+	repository := newTestRepository()
+
+	assert.NoError(runCommand([]string{"t", "Fix", "+backend", "login", "@office"}, repository, AppConfig{}))
+	assert.NoError(runCommand([]string{"t", "Write docs +docs"}, repository, AppConfig{}))
+	assert.NoError(runCommand([]string{"edit", "2", "+backend"}, repository, AppConfig{}))
+	assert.Error(runCommand([]string{"t", "+only", "@labels"}, repository, AppConfig{}))
+	assert.NoError(runCommand([]string{"listall", "+backend", "@office"}, repository, AppConfig{}))
+	assert.Error(runCommand([]string{"listall", "backend"}, repository, AppConfig{}))
+	assert.NoError(runCommand([]string{"tags"}, repository, AppConfig{}))
+
+	first, err := repository.Get(1)
+	assert.NoError(err)
+	assert.Equal("Fix login", first.Description)
+	assert.Equal([]string{"backend"}, first.Tags)
+	assert.Equal([]string{"office"}, first.Contexts)
+	second, err := repository.Get(2)
+	assert.NoError(err)
+	assert.Equal("Write docs", second.Description)
+	assert.Equal([]string{"docs", "backend"}, second.Tags)
+}
+
+func TestRunCommand_Subtasks(t *testing.T) {
+	assert := assert.New(t)
+	repository := newTestRepository()
+	repository.SetAutoComplete(true)
+
+	assert.NoError(runCommand([]string{"t", "-b", "Work", "Release"}, repository, AppConfig{}))
+	assert.NoError(runCommand([]string{"t", "--parent", "1", "Tag"}, repository, AppConfig{}))
+	assert.NoError(runCommand([]string{"t", "--parent", "1", "-b", "Home", "Announce"}, repository, AppConfig{}))
+	assert.Error(runCommand([]string{"t", "--parent", "7", "Orphan"}, repository, AppConfig{}))
+	assert.NoError(runCommand([]string{"c", "2", "3"}, repository, AppConfig{}))
+
+	child, err := repository.Get(2)
+	assert.NoError(err)
+	assert.Equal(1, child.ParentId)
+	assert.Equal([]string{"Work"}, child.Boards)
+	child, err = repository.Get(3)
+	assert.NoError(err)
+	assert.Equal([]string{"Home"}, child.Boards)
+	parent, err := repository.Get(1)
+	assert.NoError(err)
+	assert.Equal(task.StatusDone, parent.Status)
+}
+
+func TestRunCommand_DependsAndNext(t *testing.T) {
+	assert := assert.New(t)
+	repository := newTestRepository(
+		task.Task{Id: 1, Description: "Report", Boards: []string{"Work"}, Status: task.StatusPending},
+		task.Task{Id: 2, Description: "Milk", Boards: []string{"Home"}, Status: task.StatusPending},
+		task.Task{Id: 3, Description: "Review", Boards: []string{"Work"}, Status: task.StatusCancelled},
+		task.Task{Id: 4, Description: "Deploy", Boards: []string{"Work"}, Status: task.StatusPending},
+	)
+
+	assert.NoError(runCommand([]string{"depends", "1", "2", "4"}, repository, AppConfig{}))
+	assert.Error(runCommand([]string{"depends", "2", "1"}, repository, AppConfig{}))
+	assert.Error(runCommand([]string{"depends", "1"}, repository, AppConfig{}))
+	assert.NoError(runCommand([]string{"next"}, repository, AppConfig{}))
+	assert.Error(runCommand([]string{"b", "1"}, repository, AppConfig{}))
+
+	waiting, err := repository.Get(1)
+	assert.NoError(err)
+	assert.Equal([]int{2, 4}, waiting.DependsOn)
+	assert.Equal(task.StatusPending, waiting.Status)
+
+	assert.NoError(runCommand([]string{"depends", "-r", "1", "4"}, repository, AppConfig{}))
+	assert.NoError(runCommand([]string{"b", "-force", "1"}, repository, AppConfig{}))
+	started, err := repository.Get(1)
+	assert.NoError(err)
+	assert.Equal([]int{2}, started.DependsOn)
+	assert.Equal(task.StatusInProgress, started.Status)
+}
+
+func TestRunCommand_Recurring(t *testing.T) {
+	assert := assert.New(t)
+	repository := newTestRepository()
+
+	assert.NoError(runCommand([]string{"t", "--repeat", "weekly mon,thu", "Handover"}, repository, AppConfig{}))
+	assert.Error(runCommand([]string{"t", "--repeat", "yearly", "Taxes"}, repository, AppConfig{}))
+	assert.Error(runCommand([]string{"note", "--repeat", "daily", "Idea"}, repository, AppConfig{}))
+	assert.NoError(runCommand([]string{"c", "1"}, repository, AppConfig{}))
+
+	next, err := repository.Get(2)
+	assert.NoError(err)
+	assert.Equal("Handover", next.Description)
+	assert.Equal("weekly mon,thu", next.Recurrence.String())
+	assert.True(next.HasDue())
+
+	assert.NoError(runCommand([]string{"edit", "--repeat", "none", "2"}, repository, AppConfig{}))
+	assert.Error(runCommand([]string{"edit", "--repeat", "sometimes", "2"}, repository, AppConfig{}))
+	edited, err := repository.Get(2)
+	assert.NoError(err)
+	assert.False(edited.IsRecurring())
+}
+
+func TestRunCommand_Estimates(t *testing.T) {
+	assert := assert.New(t)
+	dir := t.TempDir()
+	store, err := task.NewFileStore(dir)
+	assert.NoError(err)
+	archive, err := task.NewFileArchive(dir)
+	assert.NoError(err)
+	repository := task.NewRepositoryWithArchive(store, archive)
+
+	assert.NoError(runCommand([]string{"t", "--est", "2h", "Report"}, repository, AppConfig{}))
+	assert.Error(runCommand([]string{"t", "--est", "later", "Review"}, repository, AppConfig{}))
+	assert.NoError(runCommand([]string{"edit", "--est", "90m", "1"}, repository, AppConfig{}))
+	assert.NoError(runCommand([]string{"estimates"}, repository, AppConfig{}))
+	assert.NoError(runCommand([]string{"b", "1"}, repository, AppConfig{}))
+	assert.NoError(runCommand([]string{"c", "1"}, repository, AppConfig{}))
+	assert.NoError(runCommand([]string{"clear"}, repository, AppConfig{}))
+	assert.NoError(runCommand([]string{"estimates", "-b", "My Board"}, repository, AppConfig{}))
+
+	archived, err := repository.Archived()
+	assert.NoError(err)
+	assert.Equal(90*time.Minute, archived.Tasks[0].Estimate)
+}
+
+func TestRunCommand_CreateFlagsAfterDescription(t *testing.T) {
+	assert := assert.New(t)
+	repository := newTestRepository()
+
+	assert.NoError(runCommand([]string{"t", "-b", "Work", "write report", "--due", "tomorrow", "-p", "2"}, repository, AppConfig{}))
+	assert.NoError(runCommand([]string{"t", "Check", "--", "-b", "flag"}, repository, AppConfig{}))
+
+	created, err := repository.Get(1)
+	assert.NoError(err)
+	assert.Equal("write report", created.Description)
+	assert.Equal([]string{"Work"}, created.Boards)
+	assert.True(created.HasDue())
+	assert.Equal(2, created.Priority)
+	literal, err := repository.Get(2)
+	assert.NoError(err)
+	assert.Equal("Check -b flag", literal.Description)
+	assert.Equal([]string{task.DefaultBoard}, literal.Boards)
+}
+
+func TestRunCommand_EditFlagsAfterId(t *testing.T) {
+	assert := assert.New(t)
+	repository := newTestRepository()
+	assert.NoError(runCommand([]string{"t", "Deploy service"}, repository, AppConfig{}))
+
+	assert.NoError(runCommand([]string{"edit", "1", "-p", "3"}, repository, AppConfig{}))
+	assert.NoError(runCommand([]string{"edit", "1", "--est", "1h", "Deploy", "api", "--due", "tomorrow"}, repository, AppConfig{}))
+
+	edited, err := repository.Get(1)
+	assert.NoError(err)
+	assert.Equal("Deploy api", edited.Description)
+	assert.Equal(3, edited.Priority)
+	assert.Equal(time.Hour, edited.Estimate)
+	assert.True(edited.HasDue())
+}
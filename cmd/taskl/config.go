@@ -1,5 +1,16 @@
 package main
 
+const (
+	backendJSON   = "json"
+	backendSQLite = "sqlite"
+)
+
 type AppConfig struct {
 	StoragePath string
+	// Backend is either json or sqlite, empty value picks sqlite when database exists
+	Backend string
+	// UndoDepth is number of operations which can be undone, 0 disables the journal
+	UndoDepth int
+	// AutoComplete completes parent task once all its subtasks are finished
+	AutoComplete bool
 }
@@ -5,6 +5,7 @@ import (
 	task2 "github.com/wprzechrzta/taskl/cmd/taskl/task"
 	"log"
 	"os"
+	"strconv"
 )
 
 const defualtStoragePath = "./tmp/.taskl/storage"
@@ -18,18 +19,91 @@ func Log(fmt string, args ...interface{}) {
 }
 
 func parseAndRun(args []string, config AppConfig) error {
+	store, archive, err := newStores(config)
+	if err != nil {
+		return err
+	}
+	repo := task2.NewRepositoryWithArchive(store, archive)
+	if config.UndoDepth > 0 {
+		journal, err := task2.NewFileJournal(config.StoragePath, config.UndoDepth)
+		if err != nil {
+			return err
+		}
+		repo = task2.NewRepositoryWithJournal(store, archive, journal)
+	}
+	repo.SetAutoComplete(config.AutoComplete)
+	return runCommand(args, repo, config)
+}
+
+// newStores opens task and archive stores of configured backend, when
+// backend is not set sqlite is used once database exists
+func newStores(config AppConfig) (store, archive task2.Store, err error) {
+	backend := config.Backend
+	if backend == "" {
+		backend = backendJSON
+		if task2.SQLiteExists(config.StoragePath) {
+			backend = backendSQLite
+		}
+	}
+	switch backend {
+	case backendJSON:
+		if store, err = task2.NewFileStore(config.StoragePath); err != nil {
+			return nil, nil, err
+		}
+		archive, err = task2.NewFileArchive(config.StoragePath)
+	case backendSQLite:
+		if store, err = task2.NewSQLiteStore(config.StoragePath); err != nil {
+			return nil, nil, err
+		}
+		archive, err = task2.NewSQLiteArchive(config.StoragePath)
+	default:
+		return nil, nil, fmt.Errorf("Unsupported storage backend: %s", backend)
+	}
+	if err != nil {
+		return nil, nil, err
+	}
+	return store, archive, nil
+}
+
+func runCommand(args []string, taskOperations *task2.Repository, config AppConfig) error {
 	defaultCommand := "listall"
 	if len(args) < 1 {
 		args = append(args, defaultCommand)
 	}
-	taskOperations := task2.NewRepository(config.StoragePath)
 	cmds := []ArgRunner{
 		NewListCommand(taskOperations),
+		NewFindCommand(taskOperations),
+		NewQueryCommand(taskOperations),
 		NewCreateTaskCommand(taskOperations),
+		NewCreateNoteCommand(taskOperations),
+		NewToNoteCommand(taskOperations),
+		NewToTaskCommand(taskOperations),
 		NewBeginTaskCommand(taskOperations),
+		NewPauseCommand(taskOperations),
 		NewCompleteCommand(taskOperations),
 		NewCancelCommand(taskOperations),
 		NewDeleteCommand(taskOperations),
+		NewClearCommand(taskOperations),
+		NewArchiveCommand(taskOperations),
+		NewRestoreCommand(taskOperations),
+		NewEditCommand(taskOperations),
+		NewMoveCommand(taskOperations),
+		NewCopyCommand(taskOperations),
+		NewBoardCommand(taskOperations),
+		NewPriorityCommand(taskOperations),
+		NewStarCommand(taskOperations),
+		NewUnstarCommand(taskOperations),
+		NewReopenCommand(taskOperations),
+		NewBlockCommand(taskOperations),
+		NewHistoryCommand(taskOperations),
+		NewTagsCommand(taskOperations),
+		NewDependsCommand(taskOperations),
+		NewNextCommand(taskOperations),
+		NewEstimatesCommand(taskOperations),
+		NewUndoCommand(taskOperations),
+		NewRedoCommand(taskOperations),
+		NewMigrateCommand(config),
+		NewImportCommand(taskOperations),
 	}
 
 	subcommand := args[0]
@@ -44,8 +118,42 @@ func parseAndRun(args []string, config AppConfig) error {
 	return fmt.Errorf("Provided subcommand not supported: %s", subcommand)
 }
 
+// undoDepth reads journal depth from TASKL_UNDO_DEPTH
+func undoDepth() (int, error) {
+	value := os.Getenv("TASKL_UNDO_DEPTH")
+	if value == "" {
+		return task2.DefaultJournalDepth, nil
+	}
+	depth, err := strconv.Atoi(value)
+	if err != nil || depth < 0 {
+		return 0, fmt.Errorf("TASKL_UNDO_DEPTH should be non negative integer, provided: %s", value)
+	}
+	return depth, nil
+}
+
+// autoComplete reads TASKL_AUTO_COMPLETE, parents are not completed by default
+func autoComplete() (bool, error) {
+	value := os.Getenv("TASKL_AUTO_COMPLETE")
+	if value == "" {
+		return false, nil
+	}
+	enabled, err := strconv.ParseBool(value)
+	if err != nil {
+		return false, fmt.Errorf("TASKL_AUTO_COMPLETE should be boolean, provided: %s", value)
+	}
+	return enabled, nil
+}
+
 func main() {
-	appConfig := AppConfig{StoragePath: defualtStoragePath}
+	depth, err := undoDepth()
+	if err != nil {
+		log.Fatal(err)
+	}
+	complete, err := autoComplete()
+	if err != nil {
+		log.Fatal(err)
+	}
+	appConfig := AppConfig{StoragePath: defualtStoragePath, Backend: os.Getenv("TASKL_BACKEND"), UndoDepth: depth, AutoComplete: complete}
 	if err := parseAndRun(os.Args[1:], appConfig); err != nil {
 		log.Fatalf("Failed to process request, %v", err.Error())
 	}
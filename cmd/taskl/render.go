@@ -4,68 +4,391 @@ import (
 	"fmt"
 	"github.com/wprzechrzta/taskl/cmd/taskl/task"
 	"io"
+	"sort"
+	"strings"
+	"text/tabwriter"
 	"text/template"
+	"time"
 )
 
-type TaskSummary struct {
-	Tasks       task.TaskList
+// Counts holds number of tasks in every status, notes are counted separately
+type Counts struct {
 	Total       int
 	Done        int
 	Canceled    int
 	Pending     int
 	InProgress  int
+	Paused      int
+	Blocked     int
 	DonePercent int
-	BoardName   string
+	Notes       int
+	Overdue     int
+}
+
+func (c *Counts) add(t task.Task, now time.Time) {
+	if t.IsOverdue(now) {
+		c.Overdue += 1
+	}
+	if t.IsNote {
+		c.Notes += 1
+		return
+	}
+	c.Total += 1
+	switch t.Status {
+	case task.StatusDone:
+		c.Done += 1
+	case task.StatusCancelled:
+		c.Canceled += 1
+	case task.StatusInProgress:
+		c.InProgress += 1
+	case task.StatusPaused:
+		c.Paused += 1
+	case task.StatusBlocked:
+		c.Blocked += 1
+	default:
+		c.Pending += 1
+	}
+	c.DonePercent = int((float32(c.Done) + float32(c.Canceled)) / float32(c.Total) * 100)
+}
+
+// Open returns number of tasks which are neither done nor canceled
+func (c Counts) Open() int {
+	return c.Total - c.Done - c.Canceled
+}
+
+type BoardSummary struct {
+	Name  string
+	Tasks []task.Task
+	// LocalIds maps task id to its board local number
+	LocalIds map[int]int
+	// Depth maps subtask id to its nesting level, top level tasks are not listed
+	Depth map[int]int
+	Counts
+}
+
+type TaskSummary struct {
+	// Boards are kept in order of first appearance, task attached to
+	// several boards is listed under each of them
+	Boards []BoardSummary
+	// Counts are global, every task is counted once
+	Counts
+	// Now is used to calculate elapsed time of running tasks
+	Now time.Time
+	// LocalNumbers renders board local numbers instead of task ids
+	LocalNumbers bool
+	// Highlight, when set, marks search matches in descriptions
+	Highlight func(text string) string
+	// Subtasks holds counts of direct subtasks of every parent task
+	Subtasks map[int]Counts
+	// Waiting maps open tasks to their unfinished prerequisites
+	Waiting map[int][]int
 }
 
 func calculateSummary(taskList *task.TaskList) (TaskSummary, error) {
-	summary := TaskSummary{Tasks: *taskList}
-	for _, task := range taskList.Tasks {
-		if summary.BoardName == "" {
-			summary.BoardName = task.Boards[0]
+	return calculateSummaryAt(taskList, time.Now())
+}
+
+// calculateSummaryAt calculates summary as seen at given time
+func calculateSummaryAt(taskList *task.TaskList, now time.Time) (TaskSummary, error) {
+	summary := groupTasks(taskList, now)
+	for idx := range summary.Boards {
+		board := &summary.Boards[idx]
+		sortTasks(board.Tasks)
+		board.Tasks, board.Depth = nestTasks(board.Tasks)
+	}
+	return summary, nil
+}
+
+// nestTasks moves subtasks right after their parent keeping order of
+// siblings, subtasks of parents missing from the list stay at top level
+func nestTasks(tasks []task.Task) ([]task.Task, map[int]int) {
+	present := make(map[int]bool, len(tasks))
+	for _, t := range tasks {
+		present[t.Id] = true
+	}
+	children := map[int][]task.Task{}
+	var roots []task.Task
+	for _, t := range tasks {
+		if t.ParentId != 0 && t.ParentId != t.Id && present[t.ParentId] {
+			children[t.ParentId] = append(children[t.ParentId], t)
+			continue
+		}
+		roots = append(roots, t)
+	}
+	if len(children) == 0 {
+		return tasks, nil
+	}
+	nested := make([]task.Task, 0, len(tasks))
+	depth := map[int]int{}
+	visited := map[int]bool{}
+	var visit func(t task.Task, level int)
+	visit = func(t task.Task, level int) {
+		if visited[t.Id] {
+			return
 		}
+		visited[t.Id] = true
+		nested = append(nested, t)
+		if level > 0 {
+			depth[t.Id] = level
+		}
+		for _, child := range children[t.Id] {
+			visit(child, level+1)
+		}
+	}
+	for _, t := range roots {
+		visit(t, 0)
+	}
+	// tasks caught in parent cycle are never reached from roots
+	for _, t := range tasks {
+		visit(t, 0)
+	}
+	return nested, depth
+}
 
-		if task.IsComplete {
-			summary.Done += 1
-		} else if task.IsCanelled {
-			summary.Canceled += 1
-		} else if task.InProgress {
-			summary.InProgress += 1
-		} else {
-			summary.Pending += 1
+// groupTasks groups tasks by board keeping their order within the board
+func groupTasks(taskList *task.TaskList, now time.Time) TaskSummary {
+	summary := TaskSummary{Now: now, Waiting: taskList.Waiting()}
+	boardIdx := map[string]int{}
+	for _, t := range taskList.Tasks {
+		summary.add(t, now)
+		if t.ParentId != 0 && !t.IsNote {
+			if summary.Subtasks == nil {
+				summary.Subtasks = map[int]Counts{}
+			}
+			counts := summary.Subtasks[t.ParentId]
+			counts.add(t, now)
+			summary.Subtasks[t.ParentId] = counts
+		}
+
+		boards := t.Boards
+		if len(boards) == 0 {
+			boards = []string{task.DefaultBoard}
+		}
+		for _, name := range boards {
+			idx, ok := boardIdx[name]
+			if !ok {
+				idx = len(summary.Boards)
+				boardIdx[name] = idx
+				summary.Boards = append(summary.Boards, BoardSummary{Name: name})
+			}
+			board := &summary.Boards[idx]
+			board.Tasks = append(board.Tasks, t)
+			board.add(t, now)
 		}
 	}
 
-	summary.Total = len(taskList.Tasks)
-	if summary.Total > 0 {
-		summary.DonePercent = int((float32(summary.Done) + float32(summary.Canceled)) / float32(summary.Total) * 100)
+	for idx := range summary.Boards {
+		board := &summary.Boards[idx]
+		board.LocalIds = localIds(board.Tasks)
+	}
+	return summary
+}
+
+// numberLocally assigns board local numbers counting every task of the
+// board in all, so numbers of filtered listing match those resolved by commands
+func (s *TaskSummary) numberLocally(all task.TaskList) {
+	for idx := range s.Boards {
+		board := &s.Boards[idx]
+		board.LocalIds = localIds(all.Filter(func(t task.Task) bool { return t.OnBoard(board.Name) }).Tasks)
 	}
-	return summary, nil
 }
 
-func toStatus(task task.Task) string {
-	result := "☐"
-	if task.InProgress {
-		result = "…"
-	} else if task.IsCanelled {
-		result = "✖"
-	} else if task.IsComplete {
-		result = "✓"
+// localIds numbers tasks of a board in id order starting from 1
+func localIds(tasks []task.Task) map[int]int {
+	ids := make([]int, 0, len(tasks))
+	for _, t := range tasks {
+		ids = append(ids, t.Id)
 	}
-	return result
+	sort.Ints(ids)
+	numbers := make(map[int]int, len(ids))
+	for number, id := range ids {
+		numbers[id] = number + 1
+	}
+	return numbers
+}
+
+// sortTasks puts starred tasks first, then orders by priority, highest first,
+// keeping id order within same priority
+func sortTasks(tasks []task.Task) {
+	sort.SliceStable(tasks, func(i, j int) bool {
+		if tasks[i].IsStarred != tasks[j].IsStarred {
+			return tasks[i].IsStarred
+		}
+		pi, pj := tasks[i].PriorityLevel(), tasks[j].PriorityLevel()
+		if pi != pj {
+			return pi > pj
+		}
+		return tasks[i].Id < tasks[j].Id
+	})
+}
+
+// toPriority returns marker highlighting important tasks
+func toPriority(t task.Task) string {
+	switch t.PriorityLevel() {
+	case task.PriorityMedium:
+		return " (!)"
+	case task.PriorityHigh:
+		return " (!!)"
+	}
+	return ""
+}
+
+// toProgress renders number of finished subtasks out of all subtasks
+func toProgress(counts Counts) string {
+	if counts.Total == 0 {
+		return ""
+	}
+	return fmt.Sprintf(" [%d/%d]", counts.Done+counts.Canceled, counts.Total)
+}
+
+// toLabels renders tags and contexts after description
+func toLabels(t task.Task) string {
+	labels := t.Labels()
+	if len(labels) == 0 {
+		return ""
+	}
+	return " " + strings.Join(labels, " ")
+}
+
+// toRepeat shows recurrence of the task
+func toRepeat(t task.Task) string {
+	if t.IsRecurring() {
+		return " ↻ " + t.Recurrence.String()
+	}
+	return ""
+}
+
+func toStar(t task.Task) string {
+	if t.IsStarred {
+		return " ★"
+	}
+	return ""
+}
+
+// toDue describes due date relatively to now, only open tasks are described
+func toDue(t task.Task, now time.Time) string {
+	if !t.HasDue() || t.IsNote || t.Status == task.StatusDone || t.Status == task.StatusCancelled {
+		return ""
+	}
+	days := t.DueInDays(now)
+	switch {
+	case days < 0:
+		return fmt.Sprintf(" [overdue %dd]", -days)
+	case days == 0:
+		return " [due today]"
+	case days == 1:
+		return " [due tomorrow]"
+	}
+	return fmt.Sprintf(" [due in %dd]", days)
+}
+
+// toStatus returns status marker, pending task waiting for unfinished
+// prerequisites gets its own marker
+func toStatus(t task.Task, waiting []int) string {
+	if t.IsNote {
+		return "●"
+	}
+	if len(waiting) > 0 && (t.Status == "" || t.Status == task.StatusPending) {
+		return "◌"
+	}
+	switch t.Status {
+	case task.StatusInProgress:
+		return "…"
+	case task.StatusPaused:
+		return "‖"
+	case task.StatusBlocked:
+		return "⊘"
+	case task.StatusCancelled:
+		return "✖"
+	case task.StatusDone:
+		return "✓"
+	}
+	return "☐"
+}
+
+// formatDuration prints duration rounded to minutes, shorter ones in seconds
+func formatDuration(d time.Duration) string {
+	if d < time.Minute {
+		return fmt.Sprintf("%ds", int(d.Seconds()))
+	}
+	d = d.Round(time.Minute)
+	hours, minutes := int(d.Hours()), int(d.Minutes())%60
+	switch {
+	case hours == 0:
+		return fmt.Sprintf("%dm", minutes)
+	case minutes == 0:
+		return fmt.Sprintf("%dh", hours)
+	}
+	return fmt.Sprintf("%dh %dm", hours, minutes)
+}
+
+// toEffort shows tracked time, compared with estimate when task was estimated
+func toEffort(t task.Task, now time.Time) string {
+	if t.IsNote {
+		// time tracked before conversion to note is kept but not shown
+		return ""
+	}
+	// time shorter than a second, e.g. of just started task, is not shown
+	elapsed := t.Elapsed(now)
+	switch {
+	case elapsed >= time.Second && t.HasEstimate():
+		return " (" + formatDuration(elapsed) + " / " + formatDuration(t.Estimate) + ")"
+	case elapsed >= time.Second:
+		return " (" + formatDuration(elapsed) + ")"
+	case t.HasEstimate():
+		return " (est " + formatDuration(t.Estimate) + ")"
+	}
+	return ""
 }
 
 func renderOutput(out io.Writer, summary TaskSummary) error {
-	templ := `{{.BoardName}} [{{ completedTasks .}}/{{.Total}}]
-  {{range .Tasks.Tasks}}{{ .Id}}. {{. | toStatus}} {{.Description}} (2 hours)
+	templ := `{{range $board := .Boards}}{{.Name}} [{{ completedTasks .Counts}}/{{.Total}}]
+  {{range .Tasks}}{{ indent $board . }}{{ number $board .}}. {{ status . }} {{ description . }}{{ progress . }}{{ waits . }}{{ . | toLabels}}{{ . | toPriority}}{{ . | toStar}}{{ due . }}{{ . | toRepeat}}{{ elapsed . }}
   {{end}}
-{{.Done}} done · {{.Canceled}} canceled · {{.InProgress}} in-progress · {{.Pending}} pending
+{{end}}{{.DonePercent}}% of all tasks complete.
+{{.Done}} done · {{.Canceled}} canceled · {{.InProgress}} in-progress · {{if .Paused}}{{.Paused}} paused · {{end}}{{if .Blocked}}{{.Blocked}} blocked · {{end}}{{.Pending}} pending{{if .Overdue}} · {{.Overdue}} overdue{{end}}{{if .Notes}} · {{.Notes}} notes{{end}}
 `
 
 	outputTemplate, err := template.New("output").Funcs(template.FuncMap{
-		"toStatus": toStatus,
-		"completedTasks": func(summary TaskSummary) int {
-			return summary.Done + summary.Canceled
+		"toPriority": toPriority,
+		"toStar":     toStar,
+		"toLabels":   toLabels,
+		"toRepeat":   toRepeat,
+		"completedTasks": func(counts Counts) int {
+			return counts.Done + counts.Canceled
+		},
+		"number": func(board BoardSummary, t task.Task) int {
+			if summary.LocalNumbers {
+				return board.LocalIds[t.Id]
+			}
+			return t.Id
+		},
+		"description": func(t task.Task) string {
+			if summary.Highlight != nil {
+				return summary.Highlight(t.Description)
+			}
+			return t.Description
+		},
+		"status": func(t task.Task) string {
+			return toStatus(t, summary.Waiting[t.Id])
+		},
+		"waits": func(t task.Task) string {
+			if waiting := summary.Waiting[t.Id]; len(waiting) > 0 {
+				return " (waits for " + formatIds(waiting) + ")"
+			}
+			return ""
+		},
+		"indent": func(board BoardSummary, t task.Task) string {
+			return strings.Repeat("  ", board.Depth[t.Id])
+		},
+		"progress": func(t task.Task) string {
+			return toProgress(summary.Subtasks[t.Id])
+		},
+		"due": func(t task.Task) string {
+			return toDue(t, summary.Now)
+		},
+		"elapsed": func(t task.Task) string {
+			return toEffort(t, summary.Now)
 		},
 	}).Parse(templ)
 	if err != nil {
@@ -74,3 +397,142 @@ func renderOutput(out io.Writer, summary TaskSummary) error {
 	}
 	return outputTemplate.Execute(out, summary)
 }
+
+// renderHistory prints task history one event per line
+func renderHistory(out io.Writer, t task.Task) error {
+	fmt.Fprintf(out, "Task %d: %s\n", t.Id, t.Description)
+	if len(t.History) == 0 {
+		_, err := fmt.Fprintln(out, "  No history recorded")
+		return err
+	}
+	w := tabwriter.NewWriter(out, 0, 0, 2, ' ', 0)
+	for _, e := range t.History {
+		line := fmt.Sprintf("  %s\t%s\t%s", e.At.Format("2006-01-02 15:04:05"), e.User, e.Type)
+		if e.Details != "" {
+			line += "\t" + e.Details
+		}
+		fmt.Fprintln(w, line)
+	}
+	return w.Flush()
+}
+
+// LabelSummary holds counts of tasks having +tag or @context label
+type LabelSummary struct {
+	Label string
+	Counts
+}
+
+// calculateLabelSummary counts tasks of every tag and context, tags go
+// first, both ordered by name
+func calculateLabelSummary(taskList *task.TaskList, now time.Time) []LabelSummary {
+	idx := map[string]int{}
+	var labels []LabelSummary
+	for _, t := range taskList.Tasks {
+		for _, label := range t.Labels() {
+			key := strings.ToLower(label)
+			i, ok := idx[key]
+			if !ok {
+				i = len(labels)
+				idx[key] = i
+				labels = append(labels, LabelSummary{Label: label})
+			}
+			labels[i].add(t, now)
+		}
+	}
+	sort.Slice(labels, func(i, j int) bool {
+		ti, tj := strings.HasPrefix(labels[i].Label, "+"), strings.HasPrefix(labels[j].Label, "+")
+		if ti != tj {
+			return ti
+		}
+		return strings.ToLower(labels[i].Label) < strings.ToLower(labels[j].Label)
+	})
+	return labels
+}
+
+// renderLabels prints every label with its task counts
+func renderLabels(out io.Writer, labels []LabelSummary) error {
+	w := tabwriter.NewWriter(out, 0, 0, 2, ' ', 0)
+	for _, l := range labels {
+		line := fmt.Sprintf("%s\t%d open · %d done", l.Label, l.Open(), l.Done)
+		if l.Canceled > 0 {
+			line += fmt.Sprintf(" · %d canceled", l.Canceled)
+		}
+		if l.Notes > 0 {
+			line += fmt.Sprintf(" · %d notes", l.Notes)
+		}
+		fmt.Fprintln(w, line)
+	}
+	return w.Flush()
+}
+
+// EstimateSummary compares estimated and tracked time of completed tasks
+type EstimateSummary struct {
+	Board     string
+	Tasks     int
+	Over      int
+	Under     int
+	Estimated time.Duration
+	Actual    time.Duration
+}
+
+func (e *EstimateSummary) add(t task.Task) {
+	e.Tasks += 1
+	e.Estimated += t.Estimate
+	e.Actual += t.PassedTime
+	switch {
+	case t.PassedTime > t.Estimate:
+		e.Over += 1
+	case t.PassedTime < t.Estimate:
+		e.Under += 1
+	}
+}
+
+// Ratio returns tracked time per estimated one, above 1 means overrun
+func (e EstimateSummary) Ratio() float64 {
+	if e.Estimated == 0 {
+		return 0
+	}
+	return float64(e.Actual) / float64(e.Estimated)
+}
+
+// calculateEstimates summarizes done tasks having both estimate and tracked
+// time per board in order of first appearance, last entry covers all boards
+func calculateEstimates(taskList *task.TaskList) []EstimateSummary {
+	var boards []EstimateSummary
+	total := EstimateSummary{Board: "All boards"}
+	idx := map[string]int{}
+	for _, t := range taskList.Tasks {
+		if t.IsNote || t.Status != task.StatusDone || !t.HasEstimate() || t.PassedTime == 0 {
+			continue
+		}
+		total.add(t)
+		names := t.Boards
+		if len(names) == 0 {
+			names = []string{task.DefaultBoard}
+		}
+		for _, name := range names {
+			i, ok := idx[name]
+			if !ok {
+				i = len(boards)
+				idx[name] = i
+				boards = append(boards, EstimateSummary{Board: name})
+			}
+			boards[i].add(t)
+		}
+	}
+	if len(boards) == 0 {
+		return nil
+	}
+	return append(boards, total)
+}
+
+// renderEstimates prints estimate accuracy of every board
+func renderEstimates(out io.Writer, estimates []EstimateSummary) error {
+	w := tabwriter.NewWriter(out, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "Board\tTasks\tEstimated\tActual\tRatio\tOver\tUnder")
+	for _, e := range estimates {
+		fmt.Fprintf(w, "%s\t%d\t%s\t%s\t%.2f\t%d\t%d\n", e.Board, e.Tasks, formatDuration(e.Estimated),
+			formatDuration(e.Actual), e.Ratio(), e.Over, e.Under)
+	}
+	return w.Flush()
+}
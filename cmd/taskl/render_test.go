@@ -6,6 +6,7 @@ import (
 	"github.com/stretchr/testify/assert"
 	"github.com/wprzechrzta/taskl/cmd/taskl/task"
 	"testing"
+	"time"
 )
 
 func TestCalculateSummary(t *testing.T) {
@@ -14,12 +15,12 @@ func TestCalculateSummary(t *testing.T) {
 		{
 			Description: "Example task descriptioin",
 			Boards:      []string{"Default Board"},
-			InProgress:  true,
+			Status:      task.StatusInProgress,
 		},
 		{
 			Description: "Another task",
 			Boards:      []string{"Default Board"},
-			IsCanelled:  true,
+			Status:      task.StatusCancelled,
 		},
 		{
 			Id:          5,
@@ -30,7 +31,7 @@ func TestCalculateSummary(t *testing.T) {
 			Id:          6,
 			Description: "Fifth task",
 			Boards:      []string{"Default Board"},
-			IsComplete:  true,
+			Status:      task.StatusDone,
 		},
 	}}
 
@@ -47,18 +48,18 @@ func TestCalculateSummary(t *testing.T) {
 
 func TestRenderTaskList(t *testing.T) {
 	assert := assert.New(t)
-	tasks := task.TaskList{[]task.Task{
+	tasks := task.TaskList{Tasks: []task.Task{
 		{
 			Id:          1,
 			Description: "First task to render",
 			Boards:      []string{"Default Board"},
-			InProgress:  true,
+			Status:      task.StatusInProgress,
 		},
 		{
 			Id:          2,
 			Description: "Another task",
 			Boards:      []string{"Default Board"},
-			IsCanelled:  true,
+			Status:      task.StatusCancelled,
 		},
 		{
 			Id:          5,
@@ -69,7 +70,7 @@ func TestRenderTaskList(t *testing.T) {
 			Id:          6,
 			Description: "Last task to render",
 			Boards:      []string{"Default Board"},
-			IsComplete:  true,
+			Status:      task.StatusDone,
 		},
 	}}
 
@@ -81,3 +82,354 @@ func TestRenderTaskList(t *testing.T) {
 	assert.Contains(result.String(), "Default Board")
 
 }
+
+func TestRenderPausedAndBlocked(t *testing.T) {
+	assert := assert.New(t)
+	tasks := task.TaskList{Tasks: []task.Task{
+		{Id: 1, Description: "Paused task", Boards: []string{"Default Board"}, Status: task.StatusPaused},
+		{Id: 2, Description: "Blocked task", Boards: []string{"Default Board"}, Status: task.StatusBlocked},
+	}}
+
+	summary, err := calculateSummary(&tasks)
+	assert.NoError(err)
+	assert.Equal(1, summary.Paused)
+	assert.Equal(1, summary.Blocked)
+	assert.Equal(0, summary.Pending)
+
+	var result bytes.Buffer
+	assert.NoError(renderOutput(&result, summary))
+	assert.Contains(result.String(), "1. ‖ Paused task")
+	assert.Contains(result.String(), "2. ⊘ Blocked task")
+	assert.Contains(result.String(), "1 paused · 1 blocked · 0 pending")
+}
+
+func TestRenderElapsedTime(t *testing.T) {
+	assert := assert.New(t)
+	now := time.Date(2021, 6, 8, 12, 0, 0, 0, time.UTC)
+	tasks := task.TaskList{Tasks: []task.Task{
+		{Id: 1, Description: "Running", Boards: []string{"Default Board"}, Status: task.StatusInProgress,
+			StartedAt: now.Add(-30 * time.Minute), PassedTime: 2 * time.Hour},
+		{Id: 2, Description: "Done", Boards: []string{"Default Board"}, Status: task.StatusDone, PassedTime: 45 * time.Second},
+		{Id: 3, Description: "Not started", Boards: []string{"Default Board"}},
+		{Id: 4, Description: "Just started", Boards: []string{"Default Board"}, Status: task.StatusInProgress,
+			StartedAt: now.Add(-300 * time.Millisecond)},
+	}}
+
+	summary, err := calculateSummary(&tasks)
+	assert.NoError(err)
+	summary.Now = now
+	var result bytes.Buffer
+	assert.NoError(renderOutput(&result, summary))
+	assert.Contains(result.String(), "1. … Running (2h 30m)")
+	assert.Contains(result.String(), "2. ✓ Done (45s)")
+	assert.Contains(result.String(), "3. ☐ Not started\n")
+	assert.Contains(result.String(), "4. … Just started\n")
+	assert.NotContains(result.String(), "2 hours")
+}
+
+func TestRenderGroupsTasksByBoard(t *testing.T) {
+	assert := assert.New(t)
+	tasks := task.TaskList{Tasks: []task.Task{
+		{Id: 1, Description: "Write report", Boards: []string{"Work"}, Status: task.StatusDone},
+		{Id: 2, Description: "Buy milk", Boards: []string{"Home"}},
+		{Id: 3, Description: "Call plumber", Boards: []string{"Home", "Work"}},
+		{Id: 4, Description: "No board at all"},
+	}}
+
+	summary, err := calculateSummary(&tasks)
+	assert.NoError(err)
+	assert.Equal(4, summary.Total)
+	assert.Equal(1, summary.Done)
+	assert.Equal(25, summary.DonePercent)
+	assert.Equal(3, len(summary.Boards))
+	assert.Equal("Work", summary.Boards[0].Name)
+	assert.Equal(2, summary.Boards[0].Total)
+	assert.Equal(1, summary.Boards[0].Done)
+	assert.Equal("Home", summary.Boards[1].Name)
+	assert.Equal(2, summary.Boards[1].Total)
+	assert.Equal(task.DefaultBoard, summary.Boards[2].Name)
+
+	var result bytes.Buffer
+	assert.NoError(renderOutput(&result, summary))
+	out := result.String()
+	assert.Contains(out, "Work [1/2]\n  1. ✓ Write report\n  3. ☐ Call plumber\n")
+	assert.Contains(out, "Home [0/2]\n  2. ☐ Buy milk\n  3. ☐ Call plumber\n")
+	assert.Contains(out, task.DefaultBoard+" [0/1]\n  4. ☐ No board at all\n")
+	assert.Contains(out, "25% of all tasks complete.\n1 done · 0 canceled · 0 in-progress · 3 pending")
+}
+
+func TestRenderBoardLocalNumbers(t *testing.T) {
+	assert := assert.New(t)
+	tasks := task.TaskList{Tasks: []task.Task{
+		{Id: 7, Description: "Seventh", Boards: []string{"Work"}},
+		{Id: 3, Description: "Third", Boards: []string{"Work"}},
+		{Id: 5, Description: "Fifth", Boards: []string{"Home"}},
+	}}
+
+	summary, err := calculateSummary(&tasks)
+	assert.NoError(err)
+	summary.LocalNumbers = true
+	var result bytes.Buffer
+	assert.NoError(renderOutput(&result, summary))
+	assert.Contains(result.String(), "2. ☐ Seventh")
+	assert.Contains(result.String(), "1. ☐ Third")
+	assert.Contains(result.String(), "1. ☐ Fifth")
+}
+
+func TestRenderFilteredLocalNumbersCountWholeBoard(t *testing.T) {
+	assert := assert.New(t)
+	all := task.TaskList{Tasks: []task.Task{
+		{Id: 1, Description: "First", Boards: []string{"W"}},
+		{Id: 2, Description: "Second", Boards: []string{"W"}},
+		{Id: 3, Description: "Third", Boards: []string{"W"}, IsStarred: true},
+	}}
+	starred := all.Filter(func(t task.Task) bool { return t.IsStarred })
+
+	summary, err := calculateSummary(&starred)
+	assert.NoError(err)
+	summary.LocalNumbers = true
+	summary.numberLocally(all)
+	var result bytes.Buffer
+	assert.NoError(renderOutput(&result, summary))
+	assert.Contains(result.String(), "3. ☐ Third")
+}
+
+func TestRenderSortsByPriority(t *testing.T) {
+	assert := assert.New(t)
+	tasks := task.TaskList{Tasks: []task.Task{
+		{Id: 1, Description: "Normal", Boards: []string{"Work"}},
+		{Id: 2, Description: "Urgent", Boards: []string{"Work"}, Priority: task.PriorityHigh},
+		{Id: 3, Description: "Medium", Boards: []string{"Work"}, Priority: task.PriorityMedium},
+		{Id: 4, Description: "Also normal", Boards: []string{"Work"}, Priority: task.PriorityNormal},
+	}}
+
+	summary, err := calculateSummary(&tasks)
+	assert.NoError(err)
+	var result bytes.Buffer
+	assert.NoError(renderOutput(&result, summary))
+	assert.Contains(result.String(), "Work [0/4]\n  2. ☐ Urgent (!!)\n  3. ☐ Medium (!)\n  1. ☐ Normal\n  4. ☐ Also normal\n")
+}
+
+func TestRenderStarredFirst(t *testing.T) {
+	assert := assert.New(t)
+	tasks := task.TaskList{Tasks: []task.Task{
+		{Id: 1, Description: "Urgent", Boards: []string{"Work"}, Priority: task.PriorityHigh},
+		{Id: 2, Description: "Focus", Boards: []string{"Work"}, IsStarred: true},
+		{Id: 3, Description: "Normal", Boards: []string{"Work"}},
+	}}
+
+	summary, err := calculateSummary(&tasks)
+	assert.NoError(err)
+	var result bytes.Buffer
+	assert.NoError(renderOutput(&result, summary))
+	assert.Contains(result.String(), "Work [0/3]\n  2. ☐ Focus ★\n  1. ☐ Urgent (!!)\n  3. ☐ Normal\n")
+}
+
+func TestRenderNotesExcludedFromStatistics(t *testing.T) {
+	assert := assert.New(t)
+	tasks := task.TaskList{Tasks: []task.Task{
+		{Id: 1, Description: "Task", Boards: []string{"Work"}, Status: task.StatusDone},
+		{Id: 2, Description: "Remember the milk", Boards: []string{"Work"}, IsNote: true, PassedTime: 20 * time.Minute},
+	}}
+
+	summary, err := calculateSummary(&tasks)
+	assert.NoError(err)
+	assert.Equal(1, summary.Total)
+	assert.Equal(1, summary.Notes)
+	assert.Equal(100, summary.DonePercent)
+	assert.Equal(1, summary.Boards[0].Total)
+
+	var result bytes.Buffer
+	assert.NoError(renderOutput(&result, summary))
+	assert.Contains(result.String(), "Work [1/1]\n  1. ✓ Task\n  2. ● Remember the milk\n")
+	assert.Contains(result.String(), "0 pending · 1 notes\n")
+}
+
+func TestRenderDueDates(t *testing.T) {
+	assert := assert.New(t)
+	now := time.Date(2026, 10, 14, 15, 30, 0, 0, time.Local)
+	day := func(offset int) time.Time {
+		return time.Date(2026, 10, 14+offset, 0, 0, 0, 0, time.Local)
+	}
+	tasks := task.TaskList{Tasks: []task.Task{
+		{Id: 1, Description: "Late", Boards: []string{"Work"}, DueDate: day(-2)},
+		{Id: 2, Description: "Now", Boards: []string{"Work"}, DueDate: day(0)},
+		{Id: 3, Description: "Soon", Boards: []string{"Work"}, DueDate: day(1)},
+		{Id: 4, Description: "Later", Boards: []string{"Work"}, DueDate: day(5)},
+		{Id: 5, Description: "Finished", Boards: []string{"Work"}, DueDate: day(-3), Status: task.StatusDone},
+	}}
+
+	summary, err := calculateSummaryAt(&tasks, now)
+	assert.NoError(err)
+	assert.Equal(1, summary.Overdue)
+	var result bytes.Buffer
+	assert.NoError(renderOutput(&result, summary))
+	out := result.String()
+	assert.Contains(out, "1. ☐ Late [overdue 2d]\n")
+	assert.Contains(out, "2. ☐ Now [due today]\n")
+	assert.Contains(out, "3. ☐ Soon [due tomorrow]\n")
+	assert.Contains(out, "4. ☐ Later [due in 5d]\n")
+	assert.Contains(out, "5. ✓ Finished\n")
+	assert.Contains(out, "4 pending · 1 overdue\n")
+}
+
+func TestRenderHighlight(t *testing.T) {
+	assert := assert.New(t)
+	tasks := task.TaskList{Tasks: []task.Task{
+		{Id: 1, Description: "Deploy api", Boards: []string{"Work"}},
+	}}
+	matcher, err := task.NewMatcher([]string{"api"}, false)
+	assert.NoError(err)
+
+	summary, err := calculateSummary(&tasks)
+	assert.NoError(err)
+	summary.Highlight = func(text string) string {
+		return matcher.Highlight(text, func(s string) string { return "*" + s + "*" })
+	}
+	var result bytes.Buffer
+	assert.NoError(renderOutput(&result, summary))
+	assert.Contains(result.String(), "1. ☐ Deploy *api*\n")
+}
+
+func TestGroupTasksKeepsOrder(t *testing.T) {
+	assert := assert.New(t)
+	tasks := task.TaskList{Tasks: []task.Task{
+		{Id: 3, Description: "Third", Boards: []string{"Work"}},
+		{Id: 1, Description: "First", Boards: []string{"Work"}, Priority: task.PriorityHigh},
+	}}
+
+	summary := groupTasks(&tasks, time.Now())
+	var result bytes.Buffer
+	assert.NoError(renderOutput(&result, summary))
+	assert.Contains(result.String(), "Work [0/2]\n  3. ☐ Third\n  1. ☐ First (!!)\n")
+}
+
+func TestRenderHistory(t *testing.T) {
+	assert := assert.New(t)
+	at := time.Date(2026, 10, 14, 9, 30, 0, 0, time.Local)
+	var result bytes.Buffer
+	assert.NoError(renderHistory(&result, task.Task{Id: 3, Description: "Deploy", History: []task.Event{
+		{Type: task.EventCreated, At: at, User: "ann"},
+		{Type: task.EventEdited, At: at.Add(time.Hour), User: "bob", Details: "priority 1 -> 3"},
+	}}))
+	assert.Equal("Task 3: Deploy\n"+
+		"  2026-10-14 09:30:00  ann  created\n"+
+		"  2026-10-14 10:30:00  bob  edited  priority 1 -> 3\n", result.String())
+
+	result.Reset()
+	assert.NoError(renderHistory(&result, task.Task{Id: 4, Description: "Legacy"}))
+	assert.Equal("Task 4: Legacy\n  No history recorded\n", result.String())
+}
+
+func TestRenderLabels(t *testing.T) {
+	assert := assert.New(t)
+	tasks := task.TaskList{Tasks: []task.Task{
+		{Id: 1, Description: "Login", Boards: []string{"Work"}, Tags: []string{"backend"}, Contexts: []string{"office"}},
+		{Id: 2, Description: "Api", Boards: []string{"Work"}, Tags: []string{"Backend"}, Status: task.StatusDone},
+		{Id: 3, Description: "Styles", Boards: []string{"Work"}, Tags: []string{"frontend"}, Status: task.StatusCancelled},
+	}}
+
+	summary, err := calculateSummary(&tasks)
+	assert.NoError(err)
+	var result bytes.Buffer
+	assert.NoError(renderOutput(&result, summary))
+	assert.Contains(result.String(), "1. ☐ Login +backend @office\n")
+
+	labels := calculateLabelSummary(&tasks, time.Now())
+	result.Reset()
+	assert.NoError(renderLabels(&result, labels))
+	assert.Equal("+backend   1 open · 1 done\n"+
+		"+frontend  0 open · 0 done · 1 canceled\n"+
+		"@office    1 open · 0 done\n", result.String())
+}
+
+func TestRenderSubtasks(t *testing.T) {
+	assert := assert.New(t)
+	tasks := task.TaskList{Tasks: []task.Task{
+		{Id: 1, Description: "Release", Boards: []string{"Work"}},
+		{Id: 2, Description: "Urgent", Boards: []string{"Work"}, Priority: task.PriorityHigh},
+		{Id: 3, Description: "Tag", Boards: []string{"Work"}, ParentId: 1, Status: task.StatusDone},
+		{Id: 4, Description: "Notes", Boards: []string{"Work"}, ParentId: 1, Priority: task.PriorityHigh},
+		{Id: 5, Description: "Changelog", Boards: []string{"Work"}, ParentId: 4},
+	}}
+
+	summary, err := calculateSummary(&tasks)
+	assert.NoError(err)
+	var result bytes.Buffer
+	assert.NoError(renderOutput(&result, summary))
+	assert.Contains(result.String(), "Work [1/5]\n  2. ☐ Urgent (!!)\n  1. ☐ Release [1/2]\n    4. ☐ Notes [0/1] (!!)\n      5. ☐ Changelog\n    3. ✓ Tag\n")
+}
+
+func TestRenderWaitingTasks(t *testing.T) {
+	assert := assert.New(t)
+	tasks := task.TaskList{Tasks: []task.Task{
+		{Id: 1, Description: "Design", Boards: []string{"Work"}},
+		{Id: 2, Description: "Build", Boards: []string{"Work"}, DependsOn: []int{1}},
+		{Id: 3, Description: "Ship", Boards: []string{"Work"}, DependsOn: []int{1}, Status: task.StatusBlocked},
+	}}
+
+	summary, err := calculateSummary(&tasks)
+	assert.NoError(err)
+	var result bytes.Buffer
+	assert.NoError(renderOutput(&result, summary))
+	assert.Contains(result.String(), "  1. ☐ Design\n  2. ◌ Build (waits for 1)\n  3. ⊘ Ship (waits for 1)\n")
+}
+
+func TestRenderRecurrence(t *testing.T) {
+	assert := assert.New(t)
+	tasks := task.TaskList{Tasks: []task.Task{
+		{Id: 1, Description: "Stand-up", Boards: []string{"Work"}, Recurrence: &task.Recurrence{Kind: task.RecurWeekdays}},
+		{Id: 2, Description: "Report", Boards: []string{"Work"}, Recurrence: &task.Recurrence{Kind: task.RecurWeekly, Days: []time.Weekday{time.Friday}}},
+	}}
+
+	summary, err := calculateSummary(&tasks)
+	assert.NoError(err)
+	var result bytes.Buffer
+	assert.NoError(renderOutput(&result, summary))
+	assert.Contains(result.String(), "  1. ☐ Stand-up ↻ weekdays\n  2. ☐ Report ↻ weekly fri\n")
+}
+
+func TestRenderEstimates(t *testing.T) {
+	assert := assert.New(t)
+	now := time.Date(2021, 6, 8, 12, 0, 0, 0, time.UTC)
+	tasks := task.TaskList{Tasks: []task.Task{
+		{Id: 1, Description: "Running", Boards: []string{"Work"}, Status: task.StatusInProgress,
+			StartedAt: now.Add(-30 * time.Minute), PassedTime: time.Hour, Estimate: 2 * time.Hour},
+		{Id: 2, Description: "Planned", Boards: []string{"Work"}, Estimate: 45 * time.Minute},
+	}}
+
+	summary, err := calculateSummary(&tasks)
+	assert.NoError(err)
+	summary.Now = now
+	var result bytes.Buffer
+	assert.NoError(renderOutput(&result, summary))
+	assert.Contains(result.String(), "1. … Running (1h 30m / 2h)\n")
+	assert.Contains(result.String(), "2. ☐ Planned (est 45m)\n")
+}
+
+func TestCalculateEstimates(t *testing.T) {
+	assert := assert.New(t)
+	tasks := task.TaskList{Tasks: []task.Task{
+		{Id: 1, Boards: []string{"Work"}, Status: task.StatusDone, Estimate: 2 * time.Hour, PassedTime: 3 * time.Hour},
+		{Id: 2, Boards: []string{"Work", "Home"}, Status: task.StatusDone, Estimate: 2 * time.Hour, PassedTime: time.Hour},
+		{Id: 3, Boards: []string{"Work"}, Status: task.StatusDone, Estimate: time.Hour},
+		{Id: 4, Boards: []string{"Work"}, Status: task.StatusInProgress, Estimate: time.Hour, PassedTime: time.Hour},
+		{Id: 5, Boards: []string{"Home"}, Status: task.StatusDone, PassedTime: time.Hour},
+	}}
+
+	estimates := calculateEstimates(&tasks)
+	assert.Equal([]EstimateSummary{
+		{Board: "Work", Tasks: 2, Over: 1, Under: 1, Estimated: 4 * time.Hour, Actual: 4 * time.Hour},
+		{Board: "Home", Tasks: 1, Under: 1, Estimated: 2 * time.Hour, Actual: time.Hour},
+		{Board: "All boards", Tasks: 2, Over: 1, Under: 1, Estimated: 4 * time.Hour, Actual: 4 * time.Hour},
+	}, estimates)
+	assert.Equal(0.5, estimates[1].Ratio())
+
+	var result bytes.Buffer
+	assert.NoError(renderEstimates(&result, estimates))
+	assert.Equal("Board       Tasks  Estimated  Actual  Ratio  Over  Under\n"+
+		"Work        2      4h         4h      1.00   1     1\n"+
+		"Home        1      2h         1h      0.50   0     1\n"+
+		"All boards  2      4h         4h      1.00   1     1\n", result.String())
+	assert.Empty(calculateEstimates(&task.TaskList{}))
+}
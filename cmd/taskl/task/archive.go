@@ -0,0 +1,145 @@
+package task
+
+import (
+	"github.com/pkg/errors"
+	"time"
+)
+
+// ErrNoArchive is returned by archive operations of repository created without archive store
+var ErrNoArchive = errors.New("archive is not configured")
+
+// transactBoth runs fn in transactions of task and archive stores. Stores
+// are always locked in this order, whichever direction tasks move, so
+// concurrent archive and restore can not deadlock. Archive transaction is
+// nested, it is committed first.
+func (rep *Repository) transactBoth(fn func(tx, archive Store) error) error {
+	return rep.store.Transaction(func(tx Store) error {
+		return rep.archive.Transaction(func(atx Store) error {
+			return fn(tx, atx)
+		})
+	})
+}
+
+// copyAll creates tasks of source in target keeping their ids, prepare
+// updates or rejects single task. Ids of copied tasks are returned.
+func copyAll(source, target Store, ids []int, prepare func(t *Task) error) ([]Result, []int, error) {
+	results := make([]Result, 0, len(ids))
+	var copied []int
+	for _, id := range ids {
+		task, err := source.Get(id)
+		if err == nil {
+			err = prepare(task)
+		}
+		if err == nil {
+			_, err = target.Create(*task)
+		}
+		if err != nil && !isTaskError(err) {
+			return nil, nil, err
+		}
+		if err == nil {
+			copied = append(copied, id)
+		}
+		results = append(results, Result{Id: id, Err: err})
+	}
+	return results, copied, nil
+}
+
+// deleteAll removes moved tasks from their source store
+func deleteAll(source Store, ids []int) error {
+	for _, id := range ids {
+		if err := source.Delete(id); err != nil && !errors.Is(err, ErrTaskNotFound) {
+			return err
+		}
+	}
+	return nil
+}
+
+// ArchiveAll moves tasks into archive in single transaction, when board is
+// not empty only tasks attached to it are archived
+func (rep *Repository) ArchiveAll(ids []int, board string) ([]Result, error) {
+	if rep.archive == nil {
+		return nil, ErrNoArchive
+	}
+	now := time.Now()
+	var results []Result
+	err := rep.transactBoth(func(tx, archive Store) error {
+		var archived []int
+		var err error
+		results, archived, err = copyAll(tx, archive, ids, func(t *Task) error {
+			if err := checkBoard(*t, board); err != nil {
+				return err
+			}
+			t.record(EventArchived, now, "")
+			return nil
+		})
+		if err != nil {
+			return err
+		}
+		// task store is committed after archive, failed save can leave
+		// task in both stores but never loses it
+		return deleteAll(tx, archived)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return results, nil
+}
+
+// Clear archives all done and cancelled tasks, when board is not empty
+// only tasks attached to it are archived
+func (rep *Repository) Clear(board string) ([]Result, error) {
+	tl, err := lookup(rep.store, board, []Status{StatusDone, StatusCancelled})
+	if err != nil {
+		return nil, err
+	}
+	var ids []int
+	for _, t := range tl.Tasks {
+		if !t.IsNote && !statusOf(t).IsOpen() && (board == "" || t.OnBoard(board)) {
+			ids = append(ids, t.Id)
+		}
+	}
+	if len(ids) == 0 {
+		return nil, nil
+	}
+	return rep.ArchiveAll(ids, board)
+}
+
+// Archived returns all archived tasks
+func (rep *Repository) Archived() (*TaskList, error) {
+	if rep.archive == nil {
+		return nil, ErrNoArchive
+	}
+	return rep.archive.List()
+}
+
+// RestoreAll moves tasks back from archive keeping their original ids.
+// Restored tasks are committed into task store before they are removed
+// from archive.
+func (rep *Repository) RestoreAll(ids []int) ([]Result, error) {
+	if rep.archive == nil {
+		return nil, ErrNoArchive
+	}
+	now := time.Now()
+	var results []Result
+	err := rep.journalOnce(func() error {
+		var restored []int
+		err := rep.transactBoth(func(tx, archive Store) error {
+			var err error
+			results, restored, err = copyAll(archive, tx, ids, func(t *Task) error {
+				t.record(EventRestored, now, "")
+				return nil
+			})
+			return err
+		})
+		if err != nil || len(restored) == 0 {
+			return err
+		}
+		return rep.archive.Transaction(func(archive Store) error {
+			return deleteAll(archive, restored)
+		})
+	})
+	if err != nil {
+		return nil, err
+	}
+	return results, nil
+}
@@ -0,0 +1,201 @@
+package task
+
+import (
+	"context"
+	"errors"
+	"github.com/stretchr/testify/assert"
+	"os"
+	"os/exec"
+	"strconv"
+	"testing"
+	"time"
+)
+
+func TestRepository_ClearAndRestore(t *testing.T) {
+	assert := assert.New(t)
+	dir := t.TempDir()
+	store, err := NewFileStore(dir)
+	assert.NoError(err)
+	archive, err := NewFileArchive(dir)
+	assert.NoError(err)
+	repository := NewRepositoryWithArchive(store, archive)
+	for _, task := range []Task{
+		{Id: 1, Description: "Done", Boards: []string{"Work"}, Status: StatusDone},
+		{Id: 2, Description: "Open", Boards: []string{"Work"}, Status: StatusPending},
+		{Id: 3, Description: "Cancelled", Boards: []string{"Home"}, Status: StatusCancelled},
+		{Id: 4, Description: "Last one", Boards: []string{"Home"}, Status: StatusDone},
+	} {
+		_, err := store.Create(task)
+		assert.NoError(err)
+	}
+
+	results, err := repository.Clear("Home")
+	assert.NoError(err)
+	assert.Equal([]Result{{Id: 3}, {Id: 4}}, results)
+	results, err = repository.Clear("")
+	assert.NoError(err)
+	assert.Equal([]Result{{Id: 1}}, results)
+
+	all, err := repository.GetAll()
+	assert.NoError(err)
+	assert.Equal(1, len(all.Tasks))
+	archived, err := repository.Archived()
+	assert.NoError(err)
+	assert.Equal(3, len(archived.Tasks))
+
+	created, err := repository.Create(Task{Description: "New"})
+	assert.NoError(err)
+	assert.Equal(5, created.Id)
+
+	results, err = repository.RestoreAll([]int{4, 9})
+	assert.NoError(err)
+	assert.NoError(results[0].Err)
+	assert.ErrorIs(results[1].Err, ErrTaskNotFound)
+	restored, err := repository.Get(4)
+	assert.NoError(err)
+	assert.Equal("Last one", restored.Description)
+	assert.Equal(StatusDone, restored.Status)
+	_, err = archive.Get(4)
+	assert.ErrorIs(err, ErrTaskNotFound)
+}
+
+func TestRepository_ArchiveAll(t *testing.T) {
+	assert := assert.New(t)
+	archive := NewMemoryStore(Task{Id: 2, Description: "Archived earlier"})
+	repository := NewRepositoryWithArchive(NewMemoryStore(
+		Task{Id: 1, Boards: []string{"Work"}},
+		Task{Id: 2, Boards: []string{"Work"}},
+		Task{Id: 3, Boards: []string{"Home"}},
+	), archive)
+
+	results, err := repository.ArchiveAll([]int{1, 2, 3}, "Work")
+	assert.NoError(err)
+	assert.NoError(results[0].Err)
+	assert.ErrorIs(results[1].Err, ErrTaskExists)
+	assert.ErrorIs(results[2].Err, ErrNotOnBoard)
+
+	all, err := repository.GetAll()
+	assert.NoError(err)
+	assert.Equal(2, len(all.Tasks))
+
+	results, err = repository.RestoreAll([]int{2})
+	assert.NoError(err)
+	assert.ErrorIs(results[0].Err, ErrTaskExists)
+
+	_, err = newTestRepository().ArchiveAll([]int{1}, "")
+	assert.ErrorIs(err, ErrNoArchive)
+}
+
+// failingStore rejects saving its n-th transaction, as full disk would
+type failingStore struct {
+	Store
+	commits, failAt int
+}
+
+func (s *failingStore) Transaction(fn func(tx Store) error) error {
+	return s.Store.Transaction(func(tx Store) error {
+		if err := fn(tx); err != nil {
+			return err
+		}
+		s.commits++
+		if s.commits == s.failAt {
+			return errors.New("disk full")
+		}
+		return nil
+	})
+}
+
+func TestRepository_FailedSaveKeepsMovedTask(t *testing.T) {
+	assert := assert.New(t)
+	exists := func(store Store, id int) bool {
+		_, err := store.Get(id)
+		return err == nil
+	}
+
+	//task store is saved last when archiving
+	store := NewMemoryStore(Task{Id: 1, Status: StatusDone})
+	archive := NewMemoryStore()
+	_, err := NewRepositoryWithArchive(&failingStore{Store: store, failAt: 1}, archive).ArchiveAll([]int{1}, "")
+	assert.Error(err)
+	assert.True(exists(store, 1))
+
+	//restored task is saved before it is removed from archive
+	store, archive = NewMemoryStore(), NewMemoryStore(Task{Id: 2, Status: StatusDone})
+	_, err = NewRepositoryWithArchive(&failingStore{Store: store, failAt: 1}, archive).RestoreAll([]int{2})
+	assert.Error(err)
+	assert.True(exists(archive, 2))
+
+	store, archive = NewMemoryStore(), NewMemoryStore(Task{Id: 3, Status: StatusDone})
+	_, err = NewRepositoryWithArchive(store, &failingStore{Store: archive, failAt: 2}).RestoreAll([]int{3})
+	assert.Error(err)
+	assert.True(exists(store, 3))
+}
+
+// TestArchiveHelperProcess archives or restores tasks when started as
+// separate process by TestRepository_ConcurrentArchiveAndRestore
+func TestArchiveHelperProcess(t *testing.T) {
+	dir, op := os.Getenv("TASKL_HELPER_DIR"), os.Getenv("TASKL_HELPER_OP")
+	if dir == "" {
+		t.Skip("run only as helper process")
+	}
+	store, err := NewFileStore(dir)
+	assert.NoError(t, err)
+	archive, err := NewFileArchive(dir)
+	assert.NoError(t, err)
+	repository := NewRepositoryWithArchive(store, archive)
+	first := 21
+	if op == "restore" {
+		first = 1
+	}
+	for id := first; id < first+20; id++ {
+		var results []Result
+		if op == "restore" {
+			results, err = repository.RestoreAll([]int{id})
+		} else {
+			results, err = repository.ArchiveAll([]int{id}, "")
+		}
+		assert.NoError(t, err)
+		assert.Equal(t, []Result{{Id: id}}, results)
+	}
+}
+
+func TestRepository_ConcurrentArchiveAndRestore(t *testing.T) {
+	assert := assert.New(t)
+	dir := t.TempDir()
+	store, err := NewFileStore(dir)
+	assert.NoError(err)
+	archive, err := NewFileArchive(dir)
+	assert.NoError(err)
+	repository := NewRepositoryWithArchive(store, archive)
+	var ids []int
+	for id := 1; id <= 40; id++ {
+		_, err := store.Create(Task{Id: id, Description: "Task " + strconv.Itoa(id), Status: StatusDone})
+		assert.NoError(err)
+		ids = append(ids, id)
+	}
+	_, err = repository.ArchiveAll(ids[:20], "")
+	assert.NoError(err)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+	var processes []*exec.Cmd
+	for _, op := range []string{"archive", "restore"} {
+		cmd := exec.CommandContext(ctx, os.Args[0], "-test.run=^TestArchiveHelperProcess$")
+		cmd.Env = append(os.Environ(), "TASKL_HELPER_DIR="+dir, "TASKL_HELPER_OP="+op)
+		assert.NoError(cmd.Start())
+		processes = append(processes, cmd)
+	}
+	for _, cmd := range processes {
+		assert.NoError(cmd.Wait(), "helper process failed or deadlocked")
+	}
+
+	active, err := repository.GetAll()
+	assert.NoError(err)
+	archived, err := repository.Archived()
+	assert.NoError(err)
+	assert.Len(active.Tasks, 20)
+	assert.Len(archived.Tasks, 20)
+	for _, task := range active.Tasks {
+		assert.LessOrEqual(task.Id, 20)
+	}
+}
@@ -0,0 +1,113 @@
+package task
+
+import (
+	"fmt"
+	"strings"
+	"time"
+)
+
+// boardsOf returns boards task is attached to, tasks without boards are on DefaultBoard
+func boardsOf(t Task) []string {
+	if len(t.Boards) == 0 {
+		return []string{DefaultBoard}
+	}
+	return t.Boards
+}
+
+// withBoard returns boards with given one appended unless already present
+func withBoard(boards []string, board string) []string {
+	for _, b := range boards {
+		if b == board {
+			return boards
+		}
+	}
+	return append(boards, board)
+}
+
+// replaceBoard swaps old board with new one keeping position, boards are not duplicated
+func replaceBoard(boards []string, old, new string) []string {
+	var replaced []string
+	for _, b := range boards {
+		if b == old {
+			b = new
+		}
+		replaced = withBoard(replaced, b)
+	}
+	return replaced
+}
+
+func validateBoard(board string) error {
+	if strings.TrimSpace(board) == "" {
+		return fmt.Errorf("Board name can not be empty")
+	}
+	return nil
+}
+
+// MoveAll moves tasks to given board in single transaction. When from is
+// empty tasks are detached from all other boards, otherwise only from is
+// replaced and tasks not attached to it fail.
+func (rep *Repository) MoveAll(ids []int, board, from string) ([]Result, error) {
+	if err := validateBoard(board); err != nil {
+		return nil, err
+	}
+	now := time.Now()
+	return rep.updateAll(ids, func(task *Task) error {
+		before := boardsOf(*task)
+		if from == "" {
+			task.Boards = []string{board}
+		} else if err := checkBoard(*task, from); err != nil {
+			return err
+		} else {
+			task.Boards = replaceBoard(before, from, board)
+		}
+		task.record(EventMoved, now, "%s -> %s", describeBoards(before), describeBoards(task.Boards))
+		return nil
+	})
+}
+
+// CopyAll attaches tasks to given board in single transaction, tasks stay on their current boards
+func (rep *Repository) CopyAll(ids []int, board string) ([]Result, error) {
+	if err := validateBoard(board); err != nil {
+		return nil, err
+	}
+	now := time.Now()
+	return rep.updateAll(ids, func(task *Task) error {
+		task.Boards = withBoard(append([]string(nil), boardsOf(*task)...), board)
+		task.record(EventCopied, now, "to %s", board)
+		return nil
+	})
+}
+
+// RenameBoard renames board on every task in single transaction, when
+// new board already exists both boards are merged. Number of updated
+// tasks is returned.
+func (rep *Repository) RenameBoard(old, new string) (int, error) {
+	if err := validateBoard(new); err != nil {
+		return 0, err
+	}
+	var updated int
+	now := time.Now()
+	err := rep.store.Transaction(func(tx Store) error {
+		updated = 0
+		tl, err := tx.List()
+		if err != nil {
+			return err
+		}
+		for _, t := range tl.Tasks {
+			if !t.OnBoard(old) {
+				continue
+			}
+			t.Boards = replaceBoard(boardsOf(t), old, new)
+			t.record(EventMoved, now, "board renamed %s -> %s", old, new)
+			if err := tx.Update(t); err != nil {
+				return err
+			}
+			updated++
+		}
+		if updated == 0 {
+			return fmt.Errorf("Board: %s has no tasks", old)
+		}
+		return nil
+	})
+	return updated, err
+}
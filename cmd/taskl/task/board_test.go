@@ -0,0 +1,75 @@
+package task
+
+import (
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+func TestRepository_MoveAll(t *testing.T) {
+	repository := newTestRepository(
+		Task{Id: 1, Boards: []string{"Work"}},
+		Task{Id: 2, Boards: []string{"Home", "Work"}},
+		Task{Id: 3, Boards: []string{"Home"}},
+		Task{Id: 4},
+	)
+
+	results, err := repository.MoveAll([]int{1, 2}, "Later", "")
+	assert.NoError(t, err)
+	assert.Equal(t, []Result{{Id: 1}, {Id: 2}}, results)
+
+	results, err = repository.MoveAll([]int{3, 4}, "Garden", "Home")
+	assert.NoError(t, err)
+	assert.NoError(t, results[0].Err)
+	assert.ErrorIs(t, results[1].Err, ErrNotOnBoard)
+
+	tasks := storedTasks(t, repository)
+	assert.Equal(t, []string{"Later"}, tasks[1].Boards)
+	assert.Equal(t, []string{"Later"}, tasks[2].Boards)
+	assert.Equal(t, []string{"Garden"}, tasks[3].Boards)
+	assert.Empty(t, tasks[4].Boards)
+
+	_, err = repository.MoveAll([]int{1}, " ", "")
+	assert.Error(t, err)
+}
+
+func TestRepository_CopyAll(t *testing.T) {
+	repository := newTestRepository(
+		Task{Id: 1, Boards: []string{"Work"}},
+		Task{Id: 2, Boards: []string{"Home", "Work"}},
+		Task{Id: 3, Boards: []string{"Home"}},
+		Task{Id: 4},
+	)
+
+	_, err := repository.CopyAll([]int{1, 2, 4}, "Home")
+	assert.NoError(t, err)
+
+	tasks := storedTasks(t, repository)
+	assert.Equal(t, []string{"Work", "Home"}, tasks[1].Boards)
+	assert.Equal(t, []string{"Home", "Work"}, tasks[2].Boards)
+	assert.Equal(t, []string{DefaultBoard, "Home"}, tasks[4].Boards)
+}
+
+func TestRepository_RenameBoard(t *testing.T) {
+	repository := newTestRepository(
+		Task{Id: 1, Boards: []string{"Work"}},
+		Task{Id: 2, Boards: []string{"Home", "Work"}},
+		Task{Id: 3, Boards: []string{"Home"}},
+		Task{Id: 4},
+	)
+
+	updated, err := repository.RenameBoard("Work", "Home")
+	assert.NoError(t, err)
+	assert.Equal(t, 2, updated)
+	updated, err = repository.RenameBoard(DefaultBoard, "Inbox")
+	assert.NoError(t, err)
+	assert.Equal(t, 1, updated)
+
+	tasks := storedTasks(t, repository)
+	assert.Equal(t, []string{"Home"}, tasks[1].Boards)
+	assert.Equal(t, []string{"Home"}, tasks[2].Boards)
+	assert.Equal(t, []string{"Home"}, tasks[3].Boards)
+	assert.Equal(t, []string{"Inbox"}, tasks[4].Boards)
+
+	_, err = repository.RenameBoard("Missing", "Other")
+	assert.Error(t, err)
+}
@@ -0,0 +1,201 @@
+package task
+
+import (
+	"fmt"
+	"github.com/pkg/errors"
+	"strings"
+	"time"
+)
+
+// ErrUnfinishedDependencies is returned when task is started before its prerequisites are finished
+var ErrUnfinishedDependencies = errors.New("unfinished dependencies")
+
+// ErrDependencyCycle is returned when new dependency would make task wait for itself
+var ErrDependencyCycle = errors.New("dependency cycle")
+
+// AddDependencies makes task wait for given prerequisites, dependencies
+// which would close a cycle are rejected
+func (rep *Repository) AddDependencies(id int, prerequisites []int) (*Task, error) {
+	var updated Task
+	err := rep.store.Transaction(func(tx Store) error {
+		tl, err := tx.List()
+		if err != nil {
+			return err
+		}
+		byId := make(map[int]Task, len(tl.Tasks))
+		for _, t := range tl.Tasks {
+			byId[t.Id] = t
+		}
+		task, ok := byId[id]
+		if !ok {
+			return errors.WithMessagef(ErrTaskNotFound, "Task: %d", id)
+		}
+		if task.IsNote {
+			return errors.Errorf("Task: %d is a note", id)
+		}
+		var added []int
+		for _, p := range prerequisites {
+			prerequisite, ok := byId[p]
+			switch {
+			case !ok:
+				return errors.WithMessagef(ErrTaskNotFound, "Prerequisite: %d", p)
+			case prerequisite.IsNote:
+				return errors.Errorf("Prerequisite: %d is a note", p)
+			case p == id:
+				return errors.WithMessagef(ErrDependencyCycle, "Task: %d can not depend on itself", id)
+			case dependsOn(byId, p, id):
+				return errors.WithMessagef(ErrDependencyCycle, "Task: %d already waits for %d", p, id)
+			case containsId(task.DependsOn, p):
+				continue
+			}
+			task.DependsOn = append(task.DependsOn, p)
+			added = append(added, p)
+		}
+		if len(added) == 0 {
+			updated = task
+			return nil
+		}
+		task.record(EventDepends, time.Now(), "on %s", joinIds(added))
+		updated = task
+		return tx.Update(task)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &updated, nil
+}
+
+// RemoveDependencies drops given prerequisites of the task
+func (rep *Repository) RemoveDependencies(id int, prerequisites []int) (*Task, error) {
+	var updated Task
+	err := rep.update(id, func(task *Task) error {
+		var kept, removed []int
+		for _, p := range task.DependsOn {
+			if containsId(prerequisites, p) {
+				removed = append(removed, p)
+				continue
+			}
+			kept = append(kept, p)
+		}
+		if len(removed) > 0 {
+			task.DependsOn = kept
+			task.record(EventUnlinked, time.Now(), "from %s", joinIds(removed))
+		}
+		updated = *task
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &updated, nil
+}
+
+// StartAll moves tasks in progress, tasks waiting for unfinished prerequisites are refused
+func (rep *Repository) StartAll(ids []int, board string) ([]Result, error) {
+	return rep.transitionAll(ids, StatusInProgress, board, func(tx Store, task Task) error {
+		waiting, err := unfinished(tx, task)
+		if err != nil {
+			return err
+		}
+		if len(waiting) > 0 {
+			return errors.WithMessagef(ErrUnfinishedDependencies, "Task: %d waits for %s", task.Id, joinIds(waiting))
+		}
+		return nil
+	})
+}
+
+// Unfinished returns prerequisites of the task which are not finished yet
+func (rep *Repository) Unfinished(id int) ([]int, error) {
+	task, err := rep.store.Get(id)
+	if err != nil {
+		return nil, err
+	}
+	return unfinished(rep.store, *task)
+}
+
+// unfinished returns open prerequisites, removed or archived ones are treated as finished
+func unfinished(tx Store, task Task) ([]int, error) {
+	var waiting []int
+	for _, p := range task.DependsOn {
+		prerequisite, err := tx.Get(p)
+		if errors.Is(err, ErrTaskNotFound) {
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+		if statusOf(*prerequisite).IsOpen() {
+			waiting = append(waiting, p)
+		}
+	}
+	return waiting, nil
+}
+
+// Waiting maps every open task to its unfinished prerequisites, tasks
+// missing from the list are treated as finished
+func (tl TaskList) Waiting() map[int][]int {
+	open := make(map[int]bool, len(tl.Tasks))
+	for _, t := range tl.Tasks {
+		open[t.Id] = !t.IsNote && statusOf(t).IsOpen()
+	}
+	waiting := map[int][]int{}
+	for _, t := range tl.Tasks {
+		if !open[t.Id] {
+			continue
+		}
+		for _, p := range t.DependsOn {
+			if open[p] {
+				waiting[t.Id] = append(waiting[t.Id], p)
+			}
+		}
+	}
+	return waiting
+}
+
+// Ready returns open tasks which are not blocked and have all prerequisites finished
+func (tl TaskList) Ready() TaskList {
+	waiting := tl.Waiting()
+	return tl.Filter(func(t Task) bool {
+		status := statusOf(t)
+		return !t.IsNote && status.IsOpen() && status != StatusBlocked && len(waiting[t.Id]) == 0
+	})
+}
+
+// dependsOn reports whether task from waits for target, directly or through other tasks
+func dependsOn(byId map[int]Task, from, target int) bool {
+	visited := map[int]bool{}
+	queue := []int{from}
+	for len(queue) > 0 {
+		id := queue[0]
+		queue = queue[1:]
+		if visited[id] {
+			continue
+		}
+		visited[id] = true
+		for _, p := range byId[id].DependsOn {
+			if p == target {
+				return true
+			}
+			queue = append(queue, p)
+		}
+	}
+	return false
+}
+
+func containsId(ids []int, id int) bool {
+	for _, candidate := range ids {
+		if candidate == id {
+			return true
+		}
+	}
+	return false
+}
+
+// joinIds formats ids as comma separated list
+func joinIds(ids []int) string {
+	parts := make([]string, len(ids))
+	for idx, id := range ids {
+		parts[idx] = fmt.Sprint(id)
+	}
+	return strings.Join(parts, ", ")
+}
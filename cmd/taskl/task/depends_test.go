@@ -0,0 +1,109 @@
+package task
+
+import (
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+func TestRepository_AddDependencies(t *testing.T) {
+	repository := newTestRepository(
+		Task{Id: 1, Status: StatusPending},
+		Task{Id: 2, Status: StatusPending},
+		Task{Id: 3, Status: StatusDone},
+		Task{Id: 4, Status: StatusPending, IsNote: true},
+		Task{Id: 5, Status: StatusBlocked},
+	)
+
+	updated, err := repository.AddDependencies(1, []int{2, 3})
+	assert.NoError(t, err)
+	assert.Equal(t, []int{2, 3}, updated.DependsOn)
+	assert.Equal(t, EventDepends, updated.History[len(updated.History)-1].Type)
+
+	updated, err = repository.AddDependencies(1, []int{2})
+	assert.NoError(t, err)
+	assert.Equal(t, []int{2, 3}, updated.DependsOn)
+
+	_, err = repository.AddDependencies(1, []int{9})
+	assert.ErrorIs(t, err, ErrTaskNotFound)
+	_, err = repository.AddDependencies(1, []int{4})
+	assert.Error(t, err)
+}
+
+func TestRepository_AddDependenciesDetectsCycle(t *testing.T) {
+	repository := newTestRepository(
+		Task{Id: 1, Status: StatusPending},
+		Task{Id: 2, Status: StatusPending},
+		Task{Id: 3, Status: StatusDone},
+		Task{Id: 4, Status: StatusPending, IsNote: true},
+		Task{Id: 5, Status: StatusBlocked},
+	)
+	_, err := repository.AddDependencies(1, []int{2})
+	assert.NoError(t, err)
+	_, err = repository.AddDependencies(2, []int{3})
+	assert.NoError(t, err)
+
+	_, err = repository.AddDependencies(3, []int{1})
+	assert.ErrorIs(t, err, ErrDependencyCycle)
+	_, err = repository.AddDependencies(2, []int{1})
+	assert.ErrorIs(t, err, ErrDependencyCycle)
+	_, err = repository.AddDependencies(1, []int{1})
+	assert.ErrorIs(t, err, ErrDependencyCycle)
+}
+
+func TestRepository_RemoveDependencies(t *testing.T) {
+	repository := newTestRepository(
+		Task{Id: 1, Status: StatusPending},
+		Task{Id: 2, Status: StatusPending},
+		Task{Id: 3, Status: StatusDone},
+		Task{Id: 4, Status: StatusPending, IsNote: true},
+		Task{Id: 5, Status: StatusBlocked},
+	)
+	_, err := repository.AddDependencies(1, []int{2, 3})
+	assert.NoError(t, err)
+
+	updated, err := repository.RemoveDependencies(1, []int{2, 5})
+	assert.NoError(t, err)
+	assert.Equal(t, []int{3}, updated.DependsOn)
+	assert.Equal(t, EventUnlinked, updated.History[len(updated.History)-1].Type)
+}
+
+func TestRepository_StartAll(t *testing.T) {
+	repository := newTestRepository(
+		Task{Id: 1, Status: StatusPending},
+		Task{Id: 2, Status: StatusPending},
+		Task{Id: 3, Status: StatusDone},
+		Task{Id: 4, Status: StatusPending, IsNote: true},
+		Task{Id: 5, Status: StatusBlocked},
+	)
+	_, err := repository.AddDependencies(1, []int{2, 3})
+	assert.NoError(t, err)
+
+	results, err := repository.StartAll([]int{1, 2}, "")
+	assert.NoError(t, err)
+	assert.ErrorIs(t, results[0].Err, ErrUnfinishedDependencies)
+	assert.NoError(t, results[1].Err)
+
+	waiting, err := repository.Unfinished(1)
+	assert.NoError(t, err)
+	assert.Equal(t, []int{2}, waiting)
+
+	_, err = repository.TransitionAll([]int{2}, StatusDone, "")
+	assert.NoError(t, err)
+	results, err = repository.StartAll([]int{1}, "")
+	assert.NoError(t, err)
+	assert.NoError(t, results[0].Err)
+}
+
+func TestTaskList_Ready(t *testing.T) {
+	tl := TaskList{Tasks: []Task{
+		{Id: 1, DependsOn: []int{2}},
+		{Id: 2, Status: StatusInProgress},
+		{Id: 3, Status: StatusDone},
+		{Id: 4, DependsOn: []int{3, 9}},
+		{Id: 5, Status: StatusBlocked},
+		{Id: 6, IsNote: true},
+	}}
+
+	assert.Equal(t, map[int][]int{1: {2}}, tl.Waiting())
+	assert.Equal(t, []int{2, 4}, taskIds(tl.Ready().Tasks))
+}
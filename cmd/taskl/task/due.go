@@ -0,0 +1,115 @@
+package task
+
+import (
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const dueDateLayout = "2006-01-02"
+
+var weekdays = map[string]time.Weekday{
+	"sun": time.Sunday, "sunday": time.Sunday,
+	"mon": time.Monday, "monday": time.Monday,
+	"tue": time.Tuesday, "tuesday": time.Tuesday,
+	"wed": time.Wednesday, "wednesday": time.Wednesday,
+	"thu": time.Thursday, "thursday": time.Thursday,
+	"fri": time.Friday, "friday": time.Friday,
+	"sat": time.Saturday, "saturday": time.Saturday,
+}
+
+// startOfDay returns midnight of the day t belongs to
+func startOfDay(t time.Time) time.Time {
+	year, month, day := t.Date()
+	return time.Date(year, month, day, 0, 0, 0, 0, t.Location())
+}
+
+// ParseDue converts due date given as 2026-11-01, today, tomorrow, weekday
+// name (fri, next fri), or span from today (3d, 2w, in 3 days) into date.
+// Plain weekday includes today, next weekday is always after today.
+func ParseDue(input string, now time.Time) (time.Time, error) {
+	text := strings.ToLower(strings.TrimSpace(input))
+	today := startOfDay(now)
+
+	if date, err := time.ParseInLocation(dueDateLayout, text, now.Location()); err == nil {
+		return date, nil
+	}
+	switch text {
+	case "today":
+		return today, nil
+	case "tomorrow":
+		return today.AddDate(0, 0, 1), nil
+	case "next week":
+		return today.AddDate(0, 0, 7), nil
+	case "next month":
+		return today.AddDate(0, 1, 0), nil
+	}
+
+	next := false
+	if strings.HasPrefix(text, "next ") {
+		next = true
+		text = strings.TrimPrefix(text, "next ")
+	}
+	if weekday, ok := weekdays[text]; ok {
+		days := (int(weekday) - int(today.Weekday()) + 7) % 7
+		if next && days == 0 {
+			days = 7
+		}
+		return today.AddDate(0, 0, days), nil
+	}
+	if !next {
+		if days, err := ParseDays(strings.TrimPrefix(text, "in ")); err == nil {
+			return today.AddDate(0, 0, days), nil
+		}
+	}
+	return time.Time{}, fmt.Errorf("Unsupported due date: %s", input)
+}
+
+// ParseDays reads span given as 3d, 2w, 3 days or 1 week into number of days
+func ParseDays(input string) (int, error) {
+	text := strings.ToLower(strings.TrimSpace(input))
+	units := []struct {
+		suffixes []string
+		days     int
+	}{
+		{[]string{" days", " day", "d"}, 1},
+		{[]string{" weeks", " week", "w"}, 7},
+	}
+	for _, unit := range units {
+		for _, suffix := range unit.suffixes {
+			if !strings.HasSuffix(text, suffix) {
+				continue
+			}
+			value, err := strconv.Atoi(strings.TrimSpace(strings.TrimSuffix(text, suffix)))
+			if err != nil || value < 0 {
+				return 0, fmt.Errorf("Invalid span: %s", input)
+			}
+			return value * unit.days, nil
+		}
+	}
+	return 0, fmt.Errorf("Invalid span: %s, expected e.g. 3d or 2w", input)
+}
+
+// HasDue reports whether due date was set
+func (t Task) HasDue() bool {
+	return !t.DueDate.IsZero()
+}
+
+// DueInDays returns number of days left until due date, negative when overdue
+func (t Task) DueInDays(now time.Time) int {
+	due := startOfDay(t.DueDate.In(now.Location()))
+	today := startOfDay(now)
+	return int(math.Round(due.Sub(today).Hours() / 24))
+}
+
+// IsOverdue reports whether open task passed its due date
+func (t Task) IsOverdue(now time.Time) bool {
+	return t.HasDue() && !t.IsNote && statusOf(t).IsOpen() && t.DueInDays(now) < 0
+}
+
+// DueWithin reports whether open task is due in given number of days, overdue tasks included
+func (t Task) DueWithin(days int, now time.Time) bool {
+	return t.HasDue() && !t.IsNote && statusOf(t).IsOpen() && t.DueInDays(now) <= days
+}
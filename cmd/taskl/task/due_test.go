@@ -0,0 +1,60 @@
+package task
+
+import (
+	"github.com/stretchr/testify/assert"
+	"testing"
+	"time"
+)
+
+func TestParseDue(t *testing.T) {
+	// Wednesday
+	now := time.Date(2026, 10, 14, 15, 30, 0, 0, time.Local)
+	cases := map[string]time.Time{
+		"2026-11-01": time.Date(2026, 11, 1, 0, 0, 0, 0, time.Local),
+		"today":      time.Date(2026, 10, 14, 0, 0, 0, 0, time.Local),
+		"Tomorrow":   time.Date(2026, 10, 15, 0, 0, 0, 0, time.Local),
+		"fri":        time.Date(2026, 10, 16, 0, 0, 0, 0, time.Local),
+		"wednesday":  time.Date(2026, 10, 14, 0, 0, 0, 0, time.Local),
+		"next wed":   time.Date(2026, 10, 21, 0, 0, 0, 0, time.Local),
+		"next week":  time.Date(2026, 10, 21, 0, 0, 0, 0, time.Local),
+		"next month": time.Date(2026, 11, 14, 0, 0, 0, 0, time.Local),
+		"3d":         time.Date(2026, 10, 17, 0, 0, 0, 0, time.Local),
+		"in 3 days":  time.Date(2026, 10, 17, 0, 0, 0, 0, time.Local),
+		"2w":         time.Date(2026, 10, 28, 0, 0, 0, 0, time.Local),
+		"in 1 week":  time.Date(2026, 10, 21, 0, 0, 0, 0, time.Local),
+	}
+	for input, expected := range cases {
+		due, err := ParseDue(input, now)
+		assert.NoError(t, err, input)
+		assert.True(t, expected.Equal(due), "%s: expected %v got %v", input, expected, due)
+	}
+
+	for _, input := range []string{"someday", "next 3d", "-2d", "2026-13-01"} {
+		_, err := ParseDue(input, now)
+		assert.Error(t, err, input)
+	}
+}
+
+func TestTask_Overdue(t *testing.T) {
+	assert := assert.New(t)
+	now := time.Date(2026, 10, 14, 15, 30, 0, 0, time.Local)
+	yesterday := Task{DueDate: time.Date(2026, 10, 13, 0, 0, 0, 0, time.Local)}
+	today := Task{DueDate: time.Date(2026, 10, 14, 0, 0, 0, 0, time.Local)}
+	nextWeek := Task{DueDate: time.Date(2026, 10, 21, 0, 0, 0, 0, time.Local)}
+
+	assert.Equal(-1, yesterday.DueInDays(now))
+	assert.Equal(0, today.DueInDays(now))
+	assert.Equal(7, nextWeek.DueInDays(now))
+
+	assert.True(yesterday.IsOverdue(now))
+	assert.False(today.IsOverdue(now))
+	assert.False(Task{}.IsOverdue(now))
+	done := yesterday
+	done.Status = StatusDone
+	assert.False(done.IsOverdue(now))
+
+	assert.True(yesterday.DueWithin(3, now))
+	assert.True(today.DueWithin(0, now))
+	assert.False(nextWeek.DueWithin(3, now))
+	assert.False(Task{}.DueWithin(3, now))
+}
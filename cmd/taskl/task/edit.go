@@ -0,0 +1,136 @@
+package task
+
+import (
+	"fmt"
+	"strings"
+	"time"
+)
+
+// Edit describes changes of existing task, nil fields are left untouched
+type Edit struct {
+	Description *string
+	// Boards replace all boards task is attached to
+	Boards   []string
+	Priority *int
+	// DueDate set to zero time clears the due date
+	DueDate *time.Time
+	// Recurrence set to zero value stops the task from recurring
+	Recurrence *Recurrence
+	// Estimate set to zero clears the estimate
+	Estimate *time.Duration
+	// AddTags and AddContexts are added to existing ones
+	AddTags     []string
+	AddContexts []string
+}
+
+// IsEmpty reports whether edit changes anything
+func (e Edit) IsEmpty() bool {
+	return e.Description == nil && e.Boards == nil && e.Priority == nil && e.DueDate == nil &&
+		e.Recurrence == nil && e.Estimate == nil && len(e.AddTags) == 0 && len(e.AddContexts) == 0
+}
+
+// apply validates edit and applies it to the task, description of every
+// changed field is returned
+func (e Edit) apply(t *Task) ([]string, error) {
+	var changes []string
+	if e.Description != nil {
+		description := strings.TrimSpace(*e.Description)
+		if description == "" {
+			return nil, fmt.Errorf("Task: %d, description can not be empty", t.Id)
+		}
+		if description != t.Description {
+			changes = append(changes, fmt.Sprintf("description %q -> %q", t.Description, description))
+		}
+		t.Description = description
+	}
+	if e.Boards != nil {
+		var boards []string
+		for _, board := range e.Boards {
+			if board = strings.TrimSpace(board); board != "" {
+				boards = append(boards, board)
+			}
+		}
+		if len(boards) == 0 {
+			return nil, fmt.Errorf("Task: %d, at least one board is required", t.Id)
+		}
+		if before := describeBoards(boardsOf(*t)); before != describeBoards(boards) {
+			changes = append(changes, fmt.Sprintf("boards %s -> %s", before, describeBoards(boards)))
+		}
+		t.Boards = boards
+	}
+	if e.Priority != nil {
+		if err := ValidatePriority(*e.Priority); err != nil {
+			return nil, err
+		}
+		if t.PriorityLevel() != *e.Priority {
+			changes = append(changes, fmt.Sprintf("priority %d -> %d", t.PriorityLevel(), *e.Priority))
+		}
+		t.Priority = *e.Priority
+	}
+	if e.DueDate != nil {
+		switch {
+		case e.DueDate.IsZero() && t.HasDue():
+			changes = append(changes, "due date cleared")
+		case !e.DueDate.IsZero() && !e.DueDate.Equal(t.DueDate):
+			changes = append(changes, "due "+e.DueDate.Format(dueDateLayout))
+		}
+		t.DueDate = *e.DueDate
+	}
+	if e.Recurrence != nil {
+		switch {
+		case e.Recurrence.IsZero() && t.IsRecurring():
+			changes = append(changes, "recurrence cleared")
+			t.Recurrence = nil
+		case !e.Recurrence.IsZero():
+			if !t.IsRecurring() || t.Recurrence.String() != e.Recurrence.String() {
+				changes = append(changes, "repeats "+e.Recurrence.String())
+			}
+			recurrence := *e.Recurrence
+			t.Recurrence = &recurrence
+		}
+	}
+	if e.Estimate != nil {
+		switch {
+		case *e.Estimate < 0:
+			return nil, fmt.Errorf("Task: %d, estimate can not be negative", t.Id)
+		case *e.Estimate == 0 && t.HasEstimate():
+			changes = append(changes, "estimate cleared")
+		case *e.Estimate > 0 && *e.Estimate != t.Estimate:
+			changes = append(changes, "estimate "+shortDuration(*e.Estimate))
+		}
+		t.Estimate = *e.Estimate
+	}
+	for _, tag := range e.AddTags {
+		if !t.HasTag(tag) {
+			t.Tags = append(t.Tags, tag)
+			changes = append(changes, "tag +"+tag)
+		}
+	}
+	for _, context := range e.AddContexts {
+		if !t.HasContext(context) {
+			t.Contexts = append(t.Contexts, context)
+			changes = append(changes, "context @"+context)
+		}
+	}
+	return changes, nil
+}
+
+// Edit changes fields of existing task keeping its id, date and status history
+func (rep *Repository) Edit(id int, edit Edit) (*Task, error) {
+	var edited Task
+	err := rep.update(id, func(task *Task) error {
+		changes, err := edit.apply(task)
+		if err != nil {
+			return err
+		}
+		if len(changes) > 0 {
+			task.record(EventEdited, time.Now(), strings.Join(changes, ", "))
+		}
+		edited = *task
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &edited, nil
+}
@@ -0,0 +1,49 @@
+package task
+
+import (
+	"github.com/stretchr/testify/assert"
+	"testing"
+	"time"
+)
+
+func TestRepository_Edit(t *testing.T) {
+	assert := assert.New(t)
+	created := time.Date(2021, 6, 8, 10, 0, 0, 0, time.Local)
+	repository := newTestRepository(Task{
+		Id: 1, Date: created, Description: "Typo in descriptoin", Boards: []string{"Work"},
+		Status: StatusDone, Transitions: []Transition{{From: StatusPending, To: StatusDone, At: created}},
+	})
+
+	description, priority := "Fixed description", PriorityHigh
+	due := time.Date(2026, 11, 1, 0, 0, 0, 0, time.Local)
+	edited, err := repository.Edit(1, Edit{Description: &description, Boards: []string{"Home", " "}, Priority: &priority, DueDate: &due})
+	assert.NoError(err)
+	assert.Equal("Fixed description", edited.Description)
+
+	loaded, err := repository.Get(1)
+	assert.NoError(err)
+	assert.Equal(1, loaded.Id)
+	assert.Equal(created, loaded.Date)
+	assert.Equal(StatusDone, loaded.Status)
+	assert.Equal(1, len(loaded.Transitions))
+	assert.Equal([]string{"Home"}, loaded.Boards)
+	assert.Equal(PriorityHigh, loaded.Priority)
+	assert.True(due.Equal(loaded.DueDate))
+
+	var noDue time.Time
+	_, err = repository.Edit(1, Edit{DueDate: &noDue})
+	assert.NoError(err)
+	loaded, _ = repository.Get(1)
+	assert.False(loaded.HasDue())
+	assert.Equal("Fixed description", loaded.Description)
+
+	empty, invalid := " ", 7
+	_, err = repository.Edit(1, Edit{Description: &empty})
+	assert.Error(err)
+	_, err = repository.Edit(1, Edit{Priority: &invalid})
+	assert.Error(err)
+	_, err = repository.Edit(1, Edit{Boards: []string{}})
+	assert.Error(err)
+	_, err = repository.Edit(2, Edit{Description: &description})
+	assert.ErrorIs(err, ErrTaskNotFound)
+}
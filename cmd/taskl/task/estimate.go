@@ -0,0 +1,34 @@
+package task
+
+import (
+	"fmt"
+	"strings"
+	"time"
+)
+
+// ParseEstimate reads estimated effort given as 90m, 2h, 1h30m or 1.5h
+func ParseEstimate(input string) (time.Duration, error) {
+	text := strings.ToLower(strings.ReplaceAll(strings.TrimSpace(input), " ", ""))
+	estimate, err := time.ParseDuration(text)
+	if err != nil || estimate < time.Minute {
+		return 0, fmt.Errorf("Invalid estimate: %s, expected e.g. 90m, 2h or 1h30m", input)
+	}
+	return estimate.Round(time.Minute), nil
+}
+
+// HasEstimate reports whether effort was estimated
+func (t Task) HasEstimate() bool {
+	return t.Estimate > 0
+}
+
+// shortDuration prints duration without zero minutes and seconds, e.g. 2h or 1h30m
+func shortDuration(d time.Duration) string {
+	text := d.String()
+	if strings.HasSuffix(text, "m0s") {
+		text = strings.TrimSuffix(text, "0s")
+	}
+	if strings.HasSuffix(text, "h0m") {
+		text = strings.TrimSuffix(text, "0m")
+	}
+	return text
+}
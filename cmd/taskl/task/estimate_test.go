@@ -0,0 +1,43 @@
+package task
+
+import (
+	"github.com/stretchr/testify/assert"
+	"testing"
+	"time"
+)
+
+func TestParseEstimate(t *testing.T) {
+	cases := map[string]time.Duration{
+		"90m":    90 * time.Minute,
+		"2h":     2 * time.Hour,
+		"1h30m":  90 * time.Minute,
+		"1h 30m": 90 * time.Minute,
+		"1.5H":   90 * time.Minute,
+	}
+	for input, expected := range cases {
+		estimate, err := ParseEstimate(input)
+		assert.NoError(t, err, input)
+		assert.Equal(t, expected, estimate, input)
+	}
+
+	for _, input := range []string{"", "2", "soon", "-1h", "30s"} {
+		_, err := ParseEstimate(input)
+		assert.Error(t, err, input)
+	}
+}
+
+func TestRepository_EditEstimate(t *testing.T) {
+	repository := newTestRepository(Task{Id: 1, Status: StatusPending})
+	estimate := 90 * time.Minute
+
+	edited, err := repository.Edit(1, Edit{Estimate: &estimate})
+	assert.NoError(t, err)
+	assert.Equal(t, estimate, edited.Estimate)
+	assert.Equal(t, "estimate 1h30m", edited.History[len(edited.History)-1].Details)
+
+	var cleared time.Duration
+	edited, err = repository.Edit(1, Edit{Estimate: &cleared})
+	assert.NoError(t, err)
+	assert.False(t, edited.HasEstimate())
+	assert.Equal(t, "estimate cleared", edited.History[len(edited.History)-1].Details)
+}
@@ -0,0 +1,163 @@
+package task
+
+import (
+	"encoding/json"
+	"github.com/pkg/errors"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+)
+
+const (
+	storageFilename = "taskl.json"
+	archiveFilename = "archive.json"
+)
+
+// FileStore keeps all tasks in single json file.
+// Every modification is done under advisory lock and the file is replaced
+// atomically, so concurrent processes never lose updates or see partial writes.
+type FileStore struct {
+	Path string
+}
+
+func NewFileStore(storagePath string) (*FileStore, error) {
+	return newFileStore(storagePath, storageFilename)
+}
+
+// NewFileArchive creates store of archived tasks located in storagePath
+func NewFileArchive(storagePath string) (*FileStore, error) {
+	return newFileStore(storagePath, archiveFilename)
+}
+
+func newFileStore(storagePath, filename string) (*FileStore, error) {
+	if err := os.MkdirAll(storagePath, os.ModePerm); err != nil {
+		return nil, errors.WithMessagef(err, "Failed to create storage dir, loc: %v", storagePath)
+	}
+	return &FileStore{Path: filepath.Join(storagePath, filename)}, nil
+}
+
+func (fs *FileStore) Get(id int) (*Task, error) {
+	tl, err := fs.List()
+	if err != nil {
+		return nil, err
+	}
+	idx := indexOf(tl.Tasks, id)
+	if idx < 0 {
+		return nil, notFound(id)
+	}
+	return &tl.Tasks[idx], nil
+}
+
+func (fs *FileStore) List() (*TaskList, error) {
+	if _, err := os.Stat(fs.Path); os.IsNotExist(err) {
+		return &TaskList{}, nil
+	}
+
+	var tasks TaskList
+	data, err := ioutil.ReadFile(fs.Path)
+	if err != nil {
+		return nil, errors.WithMessagef(err, "Failed to read storage file, loc: %v", fs.Path)
+	}
+	err = json.Unmarshal(data, &tasks)
+	if err != nil {
+		return nil, errors.WithMessage(err, "Failed to unmarshal storage")
+	}
+	return &tasks, nil
+}
+
+func (fs *FileStore) Create(t Task) (*Task, error) {
+	var created *Task
+	err := fs.Transaction(func(tx Store) error {
+		var err error
+		created, err = tx.Create(t)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+	return created, nil
+}
+
+func (fs *FileStore) Update(t Task) error {
+	return fs.Transaction(func(tx Store) error {
+		return tx.Update(t)
+	})
+}
+
+func (fs *FileStore) Delete(id int) error {
+	return fs.Transaction(func(tx Store) error {
+		return tx.Delete(id)
+	})
+}
+
+func (fs *FileStore) NextId() (int, error) {
+	tl, err := fs.List()
+	if err != nil {
+		return -1, err
+	}
+	return nextId(tl.Tasks), nil
+}
+
+// Transaction loads tasks while holding the lock, runs fn against in memory
+// copy and writes the result back before lock is released
+func (fs *FileStore) Transaction(fn func(tx Store) error) error {
+	unlock, err := lockFile(fs.Path + ".lock")
+	if err != nil {
+		return errors.WithMessagef(err, "FileStore: Failed to lock storage, loc: %v", fs.Path)
+	}
+	defer unlock()
+
+	tl, err := fs.List()
+	if err != nil {
+		return err
+	}
+	tx := NewMemoryStore(tl.Tasks...)
+	if err := fn(tx); err != nil {
+		return err
+	}
+	updated, err := tx.List()
+	if err != nil {
+		return err
+	}
+	return fs.save(updated)
+}
+
+func (fs *FileStore) save(list *TaskList) error {
+	data, err := json.MarshalIndent(list, "", " ")
+	if err != nil {
+		return errors.WithMessage(err, "FileStore: Failed to marshal tasks")
+	}
+	Log("save: storing  data: %v", list)
+	return writeFileAtomic(fs.Path, data, 0644)
+}
+
+// writeFileAtomic writes data to temporary file in the same directory and
+// renames it over path once content is synced to disk
+func writeFileAtomic(path string, data []byte, perm os.FileMode) error {
+	dir := filepath.Dir(path)
+	tmp, err := ioutil.TempFile(dir, "."+filepath.Base(path)+".*.tmp")
+	if err != nil {
+		return errors.WithMessage(err, "Failed to create temporary file")
+	}
+	tmpName := tmp.Name()
+	defer os.Remove(tmpName)
+
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		return errors.WithMessagef(err, "Failed to write temporary file, loc: %v", tmpName)
+	}
+	if err := tmp.Sync(); err != nil {
+		tmp.Close()
+		return errors.WithMessagef(err, "Failed to sync temporary file, loc: %v", tmpName)
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+	if err := os.Chmod(tmpName, perm); err != nil {
+		return err
+	}
+	if err := os.Rename(tmpName, path); err != nil {
+		return errors.WithMessagef(err, "Failed to replace storage file, loc: %v", path)
+	}
+	return syncDir(dir)
+}
@@ -0,0 +1,98 @@
+package task
+
+import (
+	"fmt"
+	"os"
+	"os/user"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Event types recorded in task history
+const (
+	EventCreated   = "created"
+	EventImported  = "imported"
+	EventStarted   = "started"
+	EventResumed   = "resumed"
+	EventPaused    = "paused"
+	EventBlocked   = "blocked"
+	EventCompleted = "completed"
+	EventCancelled = "cancelled"
+	EventReopened  = "reopened"
+	EventEdited    = "edited"
+	EventMoved     = "moved"
+	EventCopied    = "copied"
+	EventStarred   = "starred"
+	EventUnstarred = "unstarred"
+	EventConverted = "converted"
+	EventArchived  = "archived"
+	EventRestored  = "restored"
+	EventDepends   = "depends"
+	EventUnlinked  = "unlinked"
+	EventRecurred  = "recurred"
+)
+
+// Event is single entry of task history
+type Event struct {
+	Type    string    `json:"type"`
+	At      time.Time `json:"at"`
+	User    string    `json:"user,omitempty"`
+	Details string    `json:"details,omitempty"`
+}
+
+// currentUser returns name of OS user running the process
+var currentUser = sync.OnceValue(func() string {
+	if u, err := user.Current(); err == nil && u.Username != "" {
+		return u.Username
+	}
+	if name := os.Getenv("USER"); name != "" {
+		return name
+	}
+	return os.Getenv("USERNAME")
+})
+
+// record appends event to task history
+func (t *Task) record(eventType string, at time.Time, details string, args ...interface{}) {
+	if len(args) > 0 {
+		details = fmt.Sprintf(details, args...)
+	}
+	t.History = append(t.History, Event{Type: eventType, At: at, User: currentUser(), Details: details})
+}
+
+// transitionEvent returns event type recorded when task moves between statuses
+func transitionEvent(from, to Status) string {
+	switch to {
+	case StatusInProgress:
+		if from == StatusPaused {
+			return EventResumed
+		}
+		return EventStarted
+	case StatusPaused:
+		return EventPaused
+	case StatusBlocked:
+		return EventBlocked
+	case StatusDone:
+		return EventCompleted
+	case StatusCancelled:
+		return EventCancelled
+	}
+	return EventReopened
+}
+
+// History returns task including its history, archived tasks are looked up when task is not found
+func (rep *Repository) History(id int) (*Task, error) {
+	t, err := rep.store.Get(id)
+	if err == nil || rep.archive == nil {
+		return t, err
+	}
+	if archived, archiveErr := rep.archive.Get(id); archiveErr == nil {
+		return archived, nil
+	}
+	return nil, err
+}
+
+// describeBoards formats list of boards for history details
+func describeBoards(boards []string) string {
+	return strings.Join(boards, ", ")
+}
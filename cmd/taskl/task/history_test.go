@@ -0,0 +1,48 @@
+package task
+
+import (
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+func TestRepository_History(t *testing.T) {
+	assert := assert.New(t)
+	repository := NewRepositoryWithArchive(NewMemoryStore(), NewMemoryStore())
+
+	created, err := repository.Create(Task{Description: "Deploy", Boards: []string{"Work"}})
+	assert.NoError(err)
+	id := created.Id
+	assert.NoError(repository.Start(id))
+	assert.NoError(repository.Pause(id))
+	assert.NoError(repository.Start(id))
+	description := "Deploy api"
+	_, err = repository.Edit(id, Edit{Description: &description})
+	assert.NoError(err)
+	_, err = repository.SetPriority([]int{id}, PriorityHigh)
+	assert.NoError(err)
+	_, err = repository.MoveAll([]int{id}, "Ops", "")
+	assert.NoError(err)
+	assert.NoError(repository.Complete(id))
+	_, err = repository.ArchiveAll([]int{id}, "")
+	assert.NoError(err)
+
+	archived, err := repository.History(id)
+	assert.NoError(err)
+	var types, details []string
+	for _, e := range archived.History {
+		types = append(types, e.Type)
+		details = append(details, e.Details)
+		assert.False(e.At.IsZero())
+		assert.Equal(currentUser(), e.User)
+	}
+	assert.Equal([]string{
+		EventCreated, EventStarted, EventPaused, EventResumed, EventEdited,
+		EventEdited, EventMoved, EventCompleted, EventArchived,
+	}, types)
+	assert.Equal(`description "Deploy" -> "Deploy api"`, details[4])
+	assert.Equal("priority 1 -> 3", details[5])
+	assert.Equal("Work -> Ops", details[6])
+
+	_, err = repository.History(42)
+	assert.ErrorIs(err, ErrTaskNotFound)
+}
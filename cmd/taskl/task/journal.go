@@ -0,0 +1,489 @@
+package task
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"github.com/pkg/errors"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	journalFilename = "journal.json"
+	// DefaultJournalDepth is number of operations which can be undone by default
+	DefaultJournalDepth = 50
+
+	tasksStore   = "tasks"
+	archiveStore = "archive"
+)
+
+var (
+	ErrNothingToUndo = errors.New("nothing to undo")
+	ErrNothingToRedo = errors.New("nothing to redo")
+	// ErrJournalConflict is returned when task was changed outside of the journal since operation was recorded
+	ErrJournalConflict = errors.New("task was changed since operation was recorded")
+)
+
+// Change holds state of single task before and after operation, nil
+// Before means task was created, nil After means it was removed
+type Change struct {
+	// Store is either tasks or archive
+	Store  string `json:"store"`
+	Id     int    `json:"id"`
+	Before *Task  `json:"before"`
+	After  *Task  `json:"after"`
+}
+
+// Entry is single mutating operation recorded in journal
+type Entry struct {
+	At      time.Time `json:"at"`
+	Changes []Change  `json:"changes"`
+}
+
+// String describes entry as list of affected task ids per kind of change
+func (e Entry) String() string {
+	archived := map[int]bool{}
+	for _, c := range e.Changes {
+		if c.Store == archiveStore {
+			archived[c.Id] = true
+		}
+	}
+	kinds := map[string][]string{}
+	var order []string
+	for _, c := range e.Changes {
+		kind := "updated"
+		switch {
+		case c.Store == tasksStore && archived[c.Id] && (c.Before == nil || c.After == nil):
+			// move between stores is described by archive change
+			continue
+		case c.Before == nil && c.Store == archiveStore:
+			kind = "archived"
+		case c.After == nil && c.Store == archiveStore:
+			kind = "restored"
+		case c.Before == nil:
+			kind = "created"
+		case c.After == nil:
+			kind = "deleted"
+		}
+		if _, ok := kinds[kind]; !ok {
+			order = append(order, kind)
+		}
+		kinds[kind] = append(kinds[kind], fmt.Sprint(c.Id))
+	}
+	var parts []string
+	for _, kind := range order {
+		parts = append(parts, kind+" "+strings.Join(kinds[kind], ", "))
+	}
+	return strings.Join(parts, "; ")
+}
+
+// Journal keeps recorded operations, entries up to cursor can be undone,
+// entries after it can be redone
+type Journal interface {
+	// Record appends entry, entries which could be redone are dropped
+	Record(e Entry) error
+	// Undo runs fn with last applied entry and moves cursor back when fn succeeds
+	Undo(fn func(e Entry) error) error
+	// Redo runs fn with first undone entry and moves cursor forward when fn succeeds
+	Redo(fn func(e Entry) error) error
+}
+
+// journalState is persisted content of journal
+type journalState struct {
+	Entries []Entry `json:"entries"`
+	Cursor  int     `json:"cursor"`
+}
+
+func (s *journalState) record(e Entry, depth int) {
+	s.Entries = append(s.Entries[:s.Cursor], e)
+	if depth > 0 && len(s.Entries) > depth {
+		s.Entries = s.Entries[len(s.Entries)-depth:]
+	}
+	s.Cursor = len(s.Entries)
+}
+
+func (s *journalState) undo(fn func(e Entry) error) error {
+	if s.Cursor == 0 {
+		return ErrNothingToUndo
+	}
+	if err := fn(s.Entries[s.Cursor-1]); err != nil {
+		return err
+	}
+	s.Cursor--
+	return nil
+}
+
+func (s *journalState) redo(fn func(e Entry) error) error {
+	if s.Cursor >= len(s.Entries) {
+		return ErrNothingToRedo
+	}
+	if err := fn(s.Entries[s.Cursor]); err != nil {
+		return err
+	}
+	s.Cursor++
+	return nil
+}
+
+// MemoryJournal keeps journal in memory only, useful for tests
+type MemoryJournal struct {
+	mu    sync.Mutex
+	depth int
+	state journalState
+}
+
+func NewMemoryJournal(depth int) *MemoryJournal {
+	return &MemoryJournal{depth: depth}
+}
+
+func (mj *MemoryJournal) Record(e Entry) error {
+	mj.mu.Lock()
+	defer mj.mu.Unlock()
+	mj.state.record(e, mj.depth)
+	return nil
+}
+
+func (mj *MemoryJournal) Undo(fn func(e Entry) error) error {
+	mj.mu.Lock()
+	defer mj.mu.Unlock()
+	return mj.state.undo(fn)
+}
+
+func (mj *MemoryJournal) Redo(fn func(e Entry) error) error {
+	mj.mu.Lock()
+	defer mj.mu.Unlock()
+	return mj.state.redo(fn)
+}
+
+// FileJournal keeps journal in json file next to task storage, at most
+// Depth entries are kept
+type FileJournal struct {
+	Path  string
+	Depth int
+}
+
+func NewFileJournal(storagePath string, depth int) (*FileJournal, error) {
+	if err := os.MkdirAll(storagePath, os.ModePerm); err != nil {
+		return nil, errors.WithMessagef(err, "Failed to create storage dir, loc: %v", storagePath)
+	}
+	return &FileJournal{Path: filepath.Join(storagePath, journalFilename), Depth: depth}, nil
+}
+
+func (fj *FileJournal) Record(e Entry) error {
+	return fj.modify(func(s *journalState) error {
+		s.record(e, fj.Depth)
+		return nil
+	})
+}
+
+func (fj *FileJournal) Undo(fn func(e Entry) error) error {
+	return fj.modify(func(s *journalState) error {
+		return s.undo(fn)
+	})
+}
+
+func (fj *FileJournal) Redo(fn func(e Entry) error) error {
+	return fj.modify(func(s *journalState) error {
+		return s.redo(fn)
+	})
+}
+
+// modify loads journal under lock and writes it back when fn succeeds
+func (fj *FileJournal) modify(fn func(s *journalState) error) error {
+	unlock, err := lockFile(fj.Path + ".lock")
+	if err != nil {
+		return errors.WithMessagef(err, "FileJournal: Failed to lock journal, loc: %v", fj.Path)
+	}
+	defer unlock()
+
+	var state journalState
+	data, err := ioutil.ReadFile(fj.Path)
+	switch {
+	case err == nil:
+		if err := json.Unmarshal(data, &state); err != nil {
+			return errors.WithMessage(err, "FileJournal: Failed to unmarshal journal")
+		}
+	case !os.IsNotExist(err):
+		return errors.WithMessagef(err, "FileJournal: Failed to read journal, loc: %v", fj.Path)
+	}
+	if err := fn(&state); err != nil {
+		return err
+	}
+	data, err = json.Marshal(state)
+	if err != nil {
+		return errors.WithMessage(err, "FileJournal: Failed to marshal journal")
+	}
+	return writeFileAtomic(fj.Path, data, 0644)
+}
+
+// recorder collects changes of all stores taking part in single operation,
+// entry is recorded once outermost transaction commits
+type recorder struct {
+	journal Journal
+	depth   int
+	pending []Change
+}
+
+// journaledStore records every change made through it into journal
+type journaledStore struct {
+	Store
+	name string
+	rec  *recorder
+}
+
+func (s *journaledStore) Transaction(fn func(tx Store) error) error {
+	return s.rec.group(func() error {
+		tx := &journaledTx{name: s.name}
+		err := s.Store.Transaction(func(inner Store) error {
+			tx.Store = inner
+			tx.changes = nil
+			return fn(tx)
+		})
+		if err == nil {
+			s.rec.pending = append(s.rec.pending, tx.changes...)
+		}
+		return err
+	})
+}
+
+// group records changes of all transactions run by fn as single entry,
+// entry is written once the outermost group completes
+func (r *recorder) group(fn func() error) error {
+	r.depth++
+	err := fn()
+	r.depth--
+	if r.depth > 0 {
+		return err
+	}
+	changes := r.pending
+	r.pending = nil
+	if err != nil || len(changes) == 0 {
+		return err
+	}
+	return r.journal.Record(Entry{At: time.Now(), Changes: changes})
+}
+
+// journalOnce records changes made by fn in several transactions as single
+// journal entry, so they are undone together
+func (rep *Repository) journalOnce(fn func() error) error {
+	if journaled, ok := rep.store.(*journaledStore); ok {
+		return journaled.rec.group(fn)
+	}
+	return fn()
+}
+
+func (s *journaledStore) Create(t Task) (*Task, error) {
+	var created *Task
+	err := s.Transaction(func(tx Store) error {
+		var err error
+		created, err = tx.Create(t)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+	return created, nil
+}
+
+func (s *journaledStore) Update(t Task) error {
+	return s.Transaction(func(tx Store) error {
+		return tx.Update(t)
+	})
+}
+
+func (s *journaledStore) Delete(id int) error {
+	return s.Transaction(func(tx Store) error {
+		return tx.Delete(id)
+	})
+}
+
+// journaledTx tracks changes made in running transaction, repeated
+// changes of the same task are merged
+type journaledTx struct {
+	Store
+	name    string
+	changes []Change
+}
+
+func (tx *journaledTx) track(id int, before, after *Task) {
+	for idx := range tx.changes {
+		if tx.changes[idx].Id == id {
+			tx.changes[idx].After = after
+			return
+		}
+	}
+	tx.changes = append(tx.changes, Change{Store: tx.name, Id: id, Before: before, After: after})
+}
+
+func (tx *journaledTx) Create(t Task) (*Task, error) {
+	created, err := tx.Store.Create(t)
+	if err != nil {
+		return nil, err
+	}
+	after := created.clone()
+	tx.track(created.Id, nil, &after)
+	return created, nil
+}
+
+func (tx *journaledTx) Update(t Task) error {
+	before, err := tx.Store.Get(t.Id)
+	if err != nil {
+		return err
+	}
+	if err := tx.Store.Update(t); err != nil {
+		return err
+	}
+	after := t.clone()
+	tx.track(t.Id, before, &after)
+	return nil
+}
+
+func (tx *journaledTx) Delete(id int) error {
+	before, err := tx.Store.Get(id)
+	if err != nil {
+		return err
+	}
+	if err := tx.Store.Delete(id); err != nil {
+		return err
+	}
+	tx.track(id, before, nil)
+	return nil
+}
+
+func (tx *journaledTx) Transaction(fn func(tx Store) error) error {
+	return fn(tx)
+}
+
+// applyChanges moves every task of the entry from one state into another,
+// current state of the task has to match from. Stores are locked in the
+// same order as archive operations use, tasks first.
+func applyChanges(stores map[string]Store, changes []Change, undo bool) error {
+	var names []string
+	for _, name := range []string{tasksStore, archiveStore} {
+		if _, ok := stores[name]; ok {
+			names = append(names, name)
+		}
+	}
+
+	var apply func(idx int) error
+	apply = func(idx int) error {
+		if idx == len(names) {
+			return nil
+		}
+		name := names[idx]
+		return stores[name].Transaction(func(tx Store) error {
+			for i := range changes {
+				c := changes[i]
+				if undo {
+					c = changes[len(changes)-1-i]
+				}
+				if c.Store != name {
+					continue
+				}
+				from, to := c.Before, c.After
+				if undo {
+					from, to = c.After, c.Before
+				}
+				if err := setTask(tx, c.Id, from, to); err != nil {
+					return err
+				}
+			}
+			return apply(idx + 1)
+		})
+	}
+	return apply(0)
+}
+
+// setTask replaces task expected to be in from state with to, nil means task does not exist
+func setTask(tx Store, id int, from, to *Task) error {
+	current, err := tx.Get(id)
+	if errors.Is(err, ErrTaskNotFound) {
+		current, err = nil, nil
+	}
+	if err != nil {
+		return err
+	}
+	if !sameTask(current, from) {
+		return errors.WithMessagef(ErrJournalConflict, "Task: %d", id)
+	}
+	switch {
+	case to == nil && current != nil:
+		return tx.Delete(id)
+	case to == nil:
+		return nil
+	case current == nil:
+		_, err := tx.Create(*to)
+		return err
+	}
+	return tx.Update(*to)
+}
+
+func sameTask(a, b *Task) bool {
+	if a == nil || b == nil {
+		return a == nil && b == nil
+	}
+	ja, errA := json.Marshal(a)
+	jb, errB := json.Marshal(b)
+	return errA == nil && errB == nil && bytes.Equal(ja, jb)
+}
+
+// rawStores returns stores changes are applied to, bypassing the journal
+func (rep *Repository) rawStores() map[string]Store {
+	stores := map[string]Store{tasksStore: rep.store}
+	if rep.archive != nil {
+		stores[archiveStore] = rep.archive
+	}
+	for name, store := range stores {
+		if journaled, ok := store.(*journaledStore); ok {
+			stores[name] = journaled.Store
+		}
+	}
+	return stores
+}
+
+// NewRepositoryWithJournal creates repository recording every change into
+// journal so it can be undone, archive may be nil
+func NewRepositoryWithJournal(store, archive Store, journal Journal) *Repository {
+	rec := &recorder{journal: journal}
+	rep := &Repository{store: &journaledStore{Store: store, name: tasksStore, rec: rec}, journal: journal}
+	if archive != nil {
+		rep.archive = &journaledStore{Store: archive, name: archiveStore, rec: rec}
+	}
+	return rep
+}
+
+// Undo reverts last recorded operation
+func (rep *Repository) Undo() (*Entry, error) {
+	if rep.journal == nil {
+		return nil, errors.WithMessage(ErrNothingToUndo, "journal is disabled")
+	}
+	var undone Entry
+	err := rep.journal.Undo(func(e Entry) error {
+		undone = e
+		return applyChanges(rep.rawStores(), e.Changes, true)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &undone, nil
+}
+
+// Redo applies again last undone operation
+func (rep *Repository) Redo() (*Entry, error) {
+	if rep.journal == nil {
+		return nil, errors.WithMessage(ErrNothingToRedo, "journal is disabled")
+	}
+	var redone Entry
+	err := rep.journal.Redo(func(e Entry) error {
+		redone = e
+		return applyChanges(rep.rawStores(), e.Changes, false)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &redone, nil
+}
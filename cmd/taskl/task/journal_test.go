@@ -0,0 +1,116 @@
+package task
+
+import (
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+func TestRepository_UndoRedo(t *testing.T) {
+	assert := assert.New(t)
+	dir := t.TempDir()
+	store, err := NewFileStore(dir)
+	assert.NoError(err)
+	archive, err := NewFileArchive(dir)
+	assert.NoError(err)
+	journal, err := NewFileJournal(dir, DefaultJournalDepth)
+	assert.NoError(err)
+	repository := NewRepositoryWithJournal(store, archive, journal)
+
+	_, err = repository.Create(Task{Description: "First"})
+	assert.NoError(err)
+	_, err = repository.Create(Task{Description: "Second"})
+	assert.NoError(err)
+	_, err = repository.TransitionAll([]int{1, 2}, StatusDone, "")
+	assert.NoError(err)
+	_, err = repository.ArchiveAll([]int{1}, "")
+	assert.NoError(err)
+
+	undone, err := repository.Undo()
+	assert.NoError(err)
+	assert.Equal("archived 1", undone.String())
+	restored, err := repository.Get(1)
+	assert.NoError(err)
+	assert.Equal(StatusDone, restored.Status)
+	archived, err := repository.Archived()
+	assert.NoError(err)
+	assert.Empty(archived.Tasks)
+
+	undone, err = repository.Undo()
+	assert.NoError(err)
+	assert.Equal("updated 1, 2", undone.String())
+	first, err := repository.Get(1)
+	assert.NoError(err)
+	assert.Equal(StatusPending, first.Status)
+	assert.Empty(first.Transitions)
+
+	redone, err := repository.Redo()
+	assert.NoError(err)
+	assert.Equal("updated 1, 2", redone.String())
+	first, err = repository.Get(1)
+	assert.NoError(err)
+	assert.Equal(StatusDone, first.Status)
+
+	// new operation drops entries which could be redone
+	_, err = repository.Create(Task{Description: "Third"})
+	assert.NoError(err)
+	_, err = repository.Redo()
+	assert.ErrorIs(err, ErrNothingToRedo)
+
+	for _, expected := range []string{"created 3", "updated 1, 2", "created 2", "created 1"} {
+		undone, err = repository.Undo()
+		assert.NoError(err)
+		assert.Equal(expected, undone.String())
+	}
+	_, err = repository.Undo()
+	assert.ErrorIs(err, ErrNothingToUndo)
+	all, err := repository.GetAll()
+	assert.NoError(err)
+	assert.Empty(all.Tasks)
+}
+
+func TestRepository_UndoDepthAndConflict(t *testing.T) {
+	assert := assert.New(t)
+	store := NewMemoryStore()
+	repository := NewRepositoryWithJournal(store, nil, NewMemoryJournal(2))
+
+	for _, description := range []string{"First", "Second", "Third"} {
+		_, err := repository.Create(Task{Description: description})
+		assert.NoError(err)
+	}
+	_, err := repository.Undo()
+	assert.NoError(err)
+	_, err = repository.Undo()
+	assert.NoError(err)
+	_, err = repository.Undo()
+	assert.ErrorIs(err, ErrNothingToUndo)
+
+	_, err = repository.Redo()
+	assert.NoError(err)
+	// change made directly in the store is not journaled
+	assert.NoError(store.Update(Task{Id: 2, Description: "Changed elsewhere"}))
+	_, err = repository.Undo()
+	assert.ErrorIs(err, ErrJournalConflict)
+	changed, err := repository.Get(2)
+	assert.NoError(err)
+	assert.Equal("Changed elsewhere", changed.Description)
+}
+
+func TestRepository_UndoRestoreAsSingleEntry(t *testing.T) {
+	assert := assert.New(t)
+	repository := NewRepositoryWithJournal(NewMemoryStore(), NewMemoryStore(), NewMemoryJournal(DefaultJournalDepth))
+	_, err := repository.Create(Task{Description: "First", Status: StatusDone})
+	assert.NoError(err)
+	_, err = repository.ArchiveAll([]int{1}, "")
+	assert.NoError(err)
+	_, err = repository.RestoreAll([]int{1})
+	assert.NoError(err)
+
+	undone, err := repository.Undo()
+	assert.NoError(err)
+	assert.Len(undone.Changes, 2)
+	_, err = repository.Get(1)
+	assert.ErrorIs(err, ErrTaskNotFound)
+	archived, err := repository.Archived()
+	assert.NoError(err)
+	assert.Len(archived.Tasks, 1)
+}
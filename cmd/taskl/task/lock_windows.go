@@ -0,0 +1,33 @@
+//go:build windows
+// +build windows
+
+package task
+
+import (
+	"os"
+
+	"golang.org/x/sys/windows"
+)
+
+// lockFile takes exclusive lock on path, blocking until it is available
+func lockFile(path string) (func(), error) {
+	f, err := os.OpenFile(path, os.O_CREATE|os.O_RDWR, 0644)
+	if err != nil {
+		return nil, err
+	}
+	ol := new(windows.Overlapped)
+	handle := windows.Handle(f.Fd())
+	if err := windows.LockFileEx(handle, windows.LOCKFILE_EXCLUSIVE_LOCK, 0, 1, 0, ol); err != nil {
+		f.Close()
+		return nil, err
+	}
+	return func() {
+		windows.UnlockFileEx(handle, 0, 1, 0, ol)
+		f.Close()
+	}, nil
+}
+
+// syncDir is no-op, directories cannot be synced on windows
+func syncDir(dir string) error {
+	return nil
+}
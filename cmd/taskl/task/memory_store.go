@@ -0,0 +1,91 @@
+package task
+
+import (
+	"sync"
+)
+
+// MemoryStore keeps tasks in memory only, useful for tests
+type MemoryStore struct {
+	mu    sync.Mutex
+	tasks []Task
+}
+
+func NewMemoryStore(tasks ...Task) *MemoryStore {
+	ms := &MemoryStore{}
+	for _, t := range tasks {
+		ms.tasks = append(ms.tasks, t.clone())
+	}
+	return ms
+}
+
+func (ms *MemoryStore) Get(id int) (*Task, error) {
+	ms.mu.Lock()
+	defer ms.mu.Unlock()
+	idx := indexOf(ms.tasks, id)
+	if idx < 0 {
+		return nil, notFound(id)
+	}
+	t := ms.tasks[idx].clone()
+	return &t, nil
+}
+
+func (ms *MemoryStore) List() (*TaskList, error) {
+	ms.mu.Lock()
+	defer ms.mu.Unlock()
+	tl := &TaskList{}
+	for _, t := range ms.tasks {
+		tl.Tasks = append(tl.Tasks, t.clone())
+	}
+	return tl, nil
+}
+
+func (ms *MemoryStore) Create(t Task) (*Task, error) {
+	ms.mu.Lock()
+	defer ms.mu.Unlock()
+	if t.Id < 1 {
+		t.Id = nextId(ms.tasks)
+	} else if indexOf(ms.tasks, t.Id) >= 0 {
+		return nil, alreadyExists(t.Id)
+	}
+	ms.tasks = append(ms.tasks, t.clone())
+	return &t, nil
+}
+
+func (ms *MemoryStore) Update(t Task) error {
+	ms.mu.Lock()
+	defer ms.mu.Unlock()
+	idx := indexOf(ms.tasks, t.Id)
+	if idx < 0 {
+		return notFound(t.Id)
+	}
+	ms.tasks[idx] = t.clone()
+	return nil
+}
+
+func (ms *MemoryStore) Delete(id int) error {
+	ms.mu.Lock()
+	defer ms.mu.Unlock()
+	idx := indexOf(ms.tasks, id)
+	if idx < 0 {
+		return notFound(id)
+	}
+	ms.tasks = append(ms.tasks[:idx], ms.tasks[idx+1:]...)
+	return nil
+}
+
+func (ms *MemoryStore) NextId() (int, error) {
+	ms.mu.Lock()
+	defer ms.mu.Unlock()
+	return nextId(ms.tasks), nil
+}
+
+func (ms *MemoryStore) Transaction(fn func(tx Store) error) error {
+	ms.mu.Lock()
+	defer ms.mu.Unlock()
+	tx := NewMemoryStore(ms.tasks...)
+	if err := fn(tx); err != nil {
+		return err
+	}
+	ms.tasks = tx.tasks
+	return nil
+}
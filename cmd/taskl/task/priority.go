@@ -0,0 +1,78 @@
+package task
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	PriorityNormal = 1
+	PriorityMedium = 2
+	PriorityHigh   = 3
+)
+
+const priorityMarker = "p:"
+
+// priorityPattern matches whole p:N word, other words starting with p: are plain text
+var priorityPattern = regexp.MustCompile(`(^|\s)p:\d+(\s|$)`)
+
+// ValidatePriority returns error when priority is out of supported range
+func ValidatePriority(priority int) error {
+	if priority < PriorityNormal || priority > PriorityHigh {
+		return fmt.Errorf("Priority should be between %d and %d, provided: %d", PriorityNormal, PriorityHigh, priority)
+	}
+	return nil
+}
+
+// PriorityLevel returns task priority, tasks without priority are normal
+func (t Task) PriorityLevel() int {
+	if t.Priority == 0 {
+		return PriorityNormal
+	}
+	return t.Priority
+}
+
+// ExtractPriority removes inline p:N marker from description keeping the
+// rest of it as typed, returned priority is 0 when description has no marker
+func ExtractPriority(description string) (string, int, error) {
+	var priority int
+	for {
+		loc := priorityPattern.FindStringIndex(description)
+		if loc == nil {
+			return description, priority, nil
+		}
+		marker := strings.TrimSpace(description[loc[0]:loc[1]])
+		value, err := strconv.Atoi(strings.TrimPrefix(marker, priorityMarker))
+		if err != nil {
+			return "", 0, fmt.Errorf("Invalid priority marker: %s", marker)
+		}
+		if err := ValidatePriority(value); err != nil {
+			return "", 0, err
+		}
+		priority = value
+		// marker goes away with one of the spaces around it
+		start, end := loc[0], loc[1]
+		if start > 0 && end < len(description) {
+			end--
+		}
+		description = description[:start] + description[end:]
+	}
+}
+
+// SetPriority changes priority of all tasks in single transaction
+func (rep *Repository) SetPriority(ids []int, priority int) ([]Result, error) {
+	if err := ValidatePriority(priority); err != nil {
+		return nil, err
+	}
+	now := time.Now()
+	return rep.updateAll(ids, func(task *Task) error {
+		if task.PriorityLevel() != priority {
+			task.record(EventEdited, now, "priority %d -> %d", task.PriorityLevel(), priority)
+		}
+		task.Priority = priority
+		return nil
+	})
+}
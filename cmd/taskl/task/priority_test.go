@@ -0,0 +1,50 @@
+package task
+
+import (
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+func TestExtractPriority(t *testing.T) {
+	description, priority, err := ExtractPriority("Fix p:3 production bug")
+	assert.NoError(t, err)
+	assert.Equal(t, "Fix production bug", description)
+	assert.Equal(t, PriorityHigh, priority)
+
+	description, priority, err = ExtractPriority("No marker here")
+	assert.NoError(t, err)
+	assert.Equal(t, "No marker here", description)
+	assert.Equal(t, 0, priority)
+
+	_, _, err = ExtractPriority("Too important p:9")
+	assert.Error(t, err)
+	description, priority, err = ExtractPriority("Fix a:hover and p:first-child selectors")
+	assert.NoError(t, err)
+	assert.Equal(t, "Fix a:hover and p:first-child selectors", description)
+	assert.Equal(t, 0, priority)
+
+	description, priority, err = ExtractPriority("p:2 Keep  spacing\tas typed p:1")
+	assert.NoError(t, err)
+	assert.Equal(t, "Keep  spacing\tas typed", description)
+	assert.Equal(t, PriorityNormal, priority)
+}
+
+func TestRepository_SetPriority(t *testing.T) {
+	repository := newTestRepository(Task{Id: 1}, Task{Id: 2})
+
+	assert := assert.New(t)
+	results, err := repository.SetPriority([]int{1, 3}, PriorityMedium)
+	assert.NoError(err)
+	assert.NoError(results[0].Err)
+	assert.Error(results[1].Err)
+
+	loaded, err := repository.Get(1)
+	assert.NoError(err)
+	assert.Equal(PriorityMedium, loaded.PriorityLevel())
+	loaded, err = repository.Get(2)
+	assert.NoError(err)
+	assert.Equal(PriorityNormal, loaded.PriorityLevel())
+
+	_, err = repository.SetPriority([]int{1}, 0)
+	assert.Error(err)
+}
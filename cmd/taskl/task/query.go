@@ -0,0 +1,536 @@
+package task
+
+import (
+	"github.com/pkg/errors"
+	"math"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+	"unicode"
+)
+
+// Query is parsed query expression like
+//
+//	board:Work status:pending,in-progress priority>=2 created<7d "deploy" sort:-priority limit:10
+//
+// Terms are joined with AND unless separated with OR, NOT or - negates a
+// term and parentheses group terms. Plain words and quoted phrases are
+// searched in description, +tag and @context match labels.
+type Query struct {
+	// Root is nil when query has no conditions and matches every task
+	Root  Node
+	Sort  []SortKey
+	Limit int
+}
+
+// Node is element of parsed query evaluated against single task
+type Node interface {
+	Match(t Task) bool
+}
+
+// SortKey orders tasks by field, Desc reverses the order
+type SortKey struct {
+	Field string
+	Desc  bool
+}
+
+type andNode []Node
+
+func (n andNode) Match(t Task) bool {
+	for _, child := range n {
+		if !child.Match(t) {
+			return false
+		}
+	}
+	return true
+}
+
+type orNode []Node
+
+func (n orNode) Match(t Task) bool {
+	for _, child := range n {
+		if child.Match(t) {
+			return true
+		}
+	}
+	return false
+}
+
+type notNode struct {
+	Node
+}
+
+func (n notNode) Match(t Task) bool {
+	return !n.Node.Match(t)
+}
+
+// matchNode evaluates single field condition
+type matchNode func(t Task) bool
+
+func (n matchNode) Match(t Task) bool {
+	return n(t)
+}
+
+// Match reports whether task passes query conditions
+func (q *Query) Match(t Task) bool {
+	return q.Root == nil || q.Root.Match(t)
+}
+
+// Apply filters, sorts and limits tasks according to query
+func (q *Query) Apply(tl TaskList) TaskList {
+	result := tl.Filter(q.Match)
+	if len(q.Sort) > 0 {
+		sort.SliceStable(result.Tasks, func(i, j int) bool {
+			for _, key := range q.Sort {
+				cmp := compareBy(key.Field, result.Tasks[i], result.Tasks[j])
+				if cmp == 0 {
+					continue
+				}
+				return (cmp < 0) != key.Desc
+			}
+			return false
+		})
+	}
+	if q.Limit > 0 && len(result.Tasks) > q.Limit {
+		result.Tasks = result.Tasks[:q.Limit]
+	}
+	return result
+}
+
+// Query returns tasks matching query
+func (rep *Repository) Query(q *Query) (TaskList, error) {
+	tl, err := rep.store.List()
+	if err != nil {
+		return TaskList{}, err
+	}
+	return q.Apply(*tl), nil
+}
+
+var sortFields = map[string]bool{"id": true, "priority": true, "created": true, "due": true, "status": true, "description": true}
+
+// compareBy returns negative, zero or positive number when a is before, same or after b
+func compareBy(field string, a, b Task) int {
+	switch field {
+	case "priority":
+		return a.PriorityLevel() - b.PriorityLevel()
+	case "created":
+		return a.Date.Compare(b.Date)
+	case "due":
+		// tasks without due date go last
+		switch {
+		case a.HasDue() && b.HasDue():
+			return a.DueDate.Compare(b.DueDate)
+		case a.HasDue():
+			return -1
+		case b.HasDue():
+			return 1
+		}
+		return 0
+	case "status":
+		return strings.Compare(string(statusOf(a)), string(statusOf(b)))
+	case "description":
+		return strings.Compare(strings.ToLower(a.Description), strings.ToLower(b.Description))
+	}
+	return a.Id - b.Id
+}
+
+type token struct {
+	text string
+	// quoted tokens are always searched in description
+	quoted bool
+}
+
+// tokenize splits query into words, quoted phrases and parentheses,
+// quotes inside a word like board:"My Board" keep spaces in the value
+func tokenize(input string) ([]token, error) {
+	var tokens []token
+	runes := []rune(input)
+	for idx := 0; idx < len(runes); {
+		r := runes[idx]
+		switch {
+		case unicode.IsSpace(r):
+			idx++
+			continue
+		case r == '(' || r == ')':
+			tokens = append(tokens, token{text: string(r)})
+			idx++
+			continue
+		}
+		var text strings.Builder
+		quoted := r == '"'
+		for idx < len(runes) && !unicode.IsSpace(runes[idx]) && runes[idx] != '(' && runes[idx] != ')' {
+			if runes[idx] != '"' {
+				text.WriteRune(runes[idx])
+				idx++
+				continue
+			}
+			end := idx + 1
+			for end < len(runes) && runes[end] != '"' {
+				end++
+			}
+			if end == len(runes) {
+				return nil, errors.Errorf("Unterminated quote in query: %s", input)
+			}
+			text.WriteString(string(runes[idx+1 : end]))
+			idx = end + 1
+		}
+		tokens = append(tokens, token{text: text.String(), quoted: quoted})
+	}
+	return tokens, nil
+}
+
+var conditionPattern = regexp.MustCompile(`^([a-z]+)(>=|<=|!=|:|=|<|>)(.+)$`)
+
+// JoinArgs builds query from shell arguments, arguments containing white
+// space are quoted so each stays single condition or phrase
+func JoinArgs(args []string) string {
+	parts := make([]string, len(args))
+	for idx, arg := range args {
+		parts[idx] = arg
+		if !strings.ContainsFunc(arg, unicode.IsSpace) || strings.Contains(arg, `"`) {
+			continue
+		}
+		negation, body := "", arg
+		if strings.HasPrefix(body, "-") {
+			negation, body = "-", body[1:]
+		}
+		if m := conditionPattern.FindStringSubmatch(body); m != nil {
+			parts[idx] = negation + m[1] + m[2] + `"` + m[3] + `"`
+			continue
+		}
+		parts[idx] = negation + `"` + body + `"`
+	}
+	return strings.Join(parts, " ")
+}
+
+type queryParser struct {
+	tokens []token
+	pos    int
+	now    time.Time
+}
+
+// ParseQuery parses query expression, relative dates are resolved against now
+func ParseQuery(input string, now time.Time) (*Query, error) {
+	tokens, err := tokenize(input)
+	if err != nil {
+		return nil, err
+	}
+	q := &Query{}
+	var conditions []token
+	for _, tok := range tokens {
+		name, value, isClause := clause(tok)
+		if !isClause {
+			conditions = append(conditions, tok)
+			continue
+		}
+		switch name {
+		case "sort":
+			for _, field := range strings.Split(value, ",") {
+				key := SortKey{Field: strings.TrimPrefix(field, "-"), Desc: strings.HasPrefix(field, "-")}
+				if !sortFields[key.Field] {
+					return nil, errors.Errorf("Unsupported sort field: %s", key.Field)
+				}
+				q.Sort = append(q.Sort, key)
+			}
+		case "limit":
+			limit, err := strconv.Atoi(value)
+			if err != nil || limit < 1 {
+				return nil, errors.Errorf("Limit should be positive integer, provided: %s", value)
+			}
+			q.Limit = limit
+		}
+	}
+
+	p := &queryParser{tokens: conditions, now: now}
+	if len(conditions) == 0 {
+		return q, nil
+	}
+	if q.Root, err = p.parseOr(); err != nil {
+		return nil, err
+	}
+	if p.pos < len(p.tokens) {
+		return nil, errors.Errorf("Unexpected %q in query", p.tokens[p.pos].text)
+	}
+	return q, nil
+}
+
+// clause recognizes sort and limit clauses which apply to whole query
+func clause(tok token) (string, string, bool) {
+	if tok.quoted {
+		return "", "", false
+	}
+	for _, name := range []string{"sort", "limit"} {
+		if strings.HasPrefix(tok.text, name+":") {
+			return name, strings.TrimPrefix(tok.text, name+":"), true
+		}
+	}
+	return "", "", false
+}
+
+func (p *queryParser) peek() (token, bool) {
+	if p.pos >= len(p.tokens) {
+		return token{}, false
+	}
+	return p.tokens[p.pos], true
+}
+
+// keyword reports whether next token is unquoted keyword, case is ignored
+func (p *queryParser) keyword(name string) bool {
+	tok, ok := p.peek()
+	return ok && !tok.quoted && strings.EqualFold(tok.text, name)
+}
+
+func (p *queryParser) parseOr() (Node, error) {
+	var nodes orNode
+	for {
+		node, err := p.parseAnd()
+		if err != nil {
+			return nil, err
+		}
+		nodes = append(nodes, node)
+		if !p.keyword("or") {
+			break
+		}
+		p.pos++
+	}
+	if len(nodes) == 1 {
+		return nodes[0], nil
+	}
+	return nodes, nil
+}
+
+func (p *queryParser) parseAnd() (Node, error) {
+	var nodes andNode
+	for {
+		tok, ok := p.peek()
+		if !ok || p.keyword("or") || (!tok.quoted && tok.text == ")") {
+			break
+		}
+		if p.keyword("and") {
+			p.pos++
+			continue
+		}
+		node, err := p.parseUnary()
+		if err != nil {
+			return nil, err
+		}
+		nodes = append(nodes, node)
+	}
+	switch len(nodes) {
+	case 0:
+		return nil, errors.New("Empty expression in query")
+	case 1:
+		return nodes[0], nil
+	}
+	return nodes, nil
+}
+
+func (p *queryParser) parseUnary() (Node, error) {
+	tok, _ := p.peek()
+	if p.keyword("not") {
+		p.pos++
+		node, err := p.parseUnary()
+		if err != nil {
+			return nil, err
+		}
+		return notNode{node}, nil
+	}
+	if !tok.quoted && tok.text == "(" {
+		p.pos++
+		node, err := p.parseOr()
+		if err != nil {
+			return nil, err
+		}
+		if next, ok := p.peek(); !ok || next.quoted || next.text != ")" {
+			return nil, errors.New("Missing closing parenthesis in query")
+		}
+		p.pos++
+		return node, nil
+	}
+	p.pos++
+	if !tok.quoted && len(tok.text) > 1 && strings.HasPrefix(tok.text, "-") {
+		node, err := p.term(token{text: tok.text[1:]})
+		if err != nil {
+			return nil, err
+		}
+		return notNode{node}, nil
+	}
+	return p.term(tok)
+}
+
+// term converts single token into condition, words without field are searched in description
+func (p *queryParser) term(tok token) (Node, error) {
+	if !tok.quoted && IsTagToken(tok.text) {
+		labels := []string{tok.text}
+		return matchNode(func(t Task) bool { return t.MatchLabels(labels) }), nil
+	}
+	parts := conditionPattern.FindStringSubmatch(tok.text)
+	if tok.quoted || parts == nil {
+		return textNode(tok.text)
+	}
+	field, op, value := parts[1], parts[2], parts[3]
+	var node Node
+	var err error
+	switch field {
+	case "board":
+		node, err = boardNode(op, value)
+	case "status":
+		node, err = statusNode(op, value)
+	case "priority":
+		node, err = numberNode(op, value, func(t Task) int { return t.PriorityLevel() })
+	case "id":
+		node, err = numberNode(op, value, func(t Task) int { return t.Id })
+	case "created":
+		node, err = p.dateNode(op, value, func(t Task) (time.Time, bool) { return t.Date, true }, false)
+	case "due":
+		node, err = p.dateNode(op, value, func(t Task) (time.Time, bool) { return t.DueDate, t.HasDue() }, true)
+	case "tag":
+		node, err = equality(op, func(t Task) bool { return t.HasTag(value) })
+	case "context":
+		node, err = equality(op, func(t Task) bool { return t.HasContext(value) })
+	case "is":
+		node, err = p.isNode(op, value)
+	case "text":
+		node, err = textNode(value)
+	default:
+		return nil, errors.Errorf("Unknown query field: %s", field)
+	}
+	if err != nil {
+		return nil, errors.WithMessagef(err, "Invalid condition: %s", tok.text)
+	}
+	return node, nil
+}
+
+func textNode(text string) (Node, error) {
+	matcher, err := NewMatcher([]string{text}, false)
+	if err != nil {
+		return nil, err
+	}
+	return matchNode(matcher.Match), nil
+}
+
+// equality builds node for : = and != operators only
+func equality(op string, match func(t Task) bool) (Node, error) {
+	switch op {
+	case ":", "=":
+		return matchNode(match), nil
+	case "!=":
+		return notNode{matchNode(match)}, nil
+	}
+	return nil, errors.Errorf("Operator %s is not supported", op)
+}
+
+func boardNode(op, value string) (Node, error) {
+	return equality(op, func(t Task) bool {
+		for _, board := range boardsOf(t) {
+			if strings.EqualFold(board, value) {
+				return true
+			}
+		}
+		return false
+	})
+}
+
+func statusNode(op, value string) (Node, error) {
+	var statuses []Status
+	for _, name := range strings.Split(value, ",") {
+		status, err := ParseStatus(name)
+		if err != nil {
+			return nil, err
+		}
+		statuses = append(statuses, status)
+	}
+	return equality(op, func(t Task) bool {
+		for _, status := range statuses {
+			if !t.IsNote && statusOf(t) == status {
+				return true
+			}
+		}
+		return false
+	})
+}
+
+// compare applies comparison operator to a and b
+func compare(op string, a, b int) bool {
+	switch op {
+	case "<":
+		return a < b
+	case "<=":
+		return a <= b
+	case ">":
+		return a > b
+	case ">=":
+		return a >= b
+	case "!=":
+		return a != b
+	}
+	return a == b
+}
+
+func numberNode(op, value string, field func(t Task) int) (Node, error) {
+	number, err := strconv.Atoi(value)
+	if err != nil {
+		return nil, errors.Errorf("Expected integer value, provided: %s", value)
+	}
+	return matchNode(func(t Task) bool { return compare(op, field(t), number) }), nil
+}
+
+// dateNode compares day of the task date. Date is given as 2026-11-01,
+// today, yesterday, tomorrow or as span like 7d. Span is age of past
+// dates, created<7d are tasks younger than 7 days, and days left for
+// future ones, due<3d are tasks due in less than 3 days. Tasks without
+// date do not match, due:none matches them instead.
+func (p *queryParser) dateNode(op, value string, field func(t Task) (time.Time, bool), future bool) (Node, error) {
+	if value == "none" {
+		return equality(op, func(t Task) bool {
+			_, ok := field(t)
+			return !ok
+		})
+	}
+	today := startOfDay(p.now)
+	daysFrom := func(date time.Time) int {
+		return int(math.Round(startOfDay(date.In(p.now.Location())).Sub(today).Hours() / 24))
+	}
+
+	offset := daysFrom
+	target, err := ParseDays(value)
+	switch {
+	case err == nil && !future:
+		offset = func(date time.Time) int { return -daysFrom(date) }
+	case err != nil:
+		date, err := p.parseDay(value)
+		if err != nil {
+			return nil, err
+		}
+		target = daysFrom(date)
+	}
+	return matchNode(func(t Task) bool {
+		date, ok := field(t)
+		return ok && compare(op, offset(date), target)
+	}), nil
+}
+
+func (p *queryParser) parseDay(value string) (time.Time, error) {
+	if strings.EqualFold(value, "tomorrow") {
+		return startOfDay(p.now).AddDate(0, 0, 1), nil
+	}
+	return ParseDate(value, p.now)
+}
+
+// isNode checks task flags: starred, note, task, open and overdue
+func (p *queryParser) isNode(op, value string) (Node, error) {
+	flags := map[string]func(t Task) bool{
+		"starred": func(t Task) bool { return t.IsStarred },
+		"note":    func(t Task) bool { return t.IsNote },
+		"task":    func(t Task) bool { return !t.IsNote },
+		"open":    func(t Task) bool { return !t.IsNote && statusOf(t).IsOpen() },
+		"overdue": func(t Task) bool { return t.IsOverdue(p.now) },
+	}
+	flag, ok := flags[strings.ToLower(value)]
+	if !ok {
+		return nil, errors.Errorf("Unknown flag: %s", value)
+	}
+	return equality(op, flag)
+}
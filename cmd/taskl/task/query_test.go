@@ -0,0 +1,112 @@
+package task
+
+import (
+	"github.com/stretchr/testify/assert"
+	"testing"
+	"time"
+)
+
+func queryTasks(now time.Time) TaskList {
+	return TaskList{Tasks: []Task{
+		{Id: 1, Description: "Deploy api", Boards: []string{"Work"}, Date: now.AddDate(0, 0, -10), Priority: PriorityHigh},
+		{Id: 2, Description: "Deploy web", Boards: []string{"Work"}, Date: now.AddDate(0, 0, -2), Status: StatusDone, Priority: PriorityMedium},
+		{Id: 3, Description: "Buy milk", Boards: []string{"My Board"}, Date: now, DueDate: startOfDay(now).AddDate(0, 0, 1), IsStarred: true},
+		{Id: 4, Description: "Pay deploy bill", Boards: []string{"Home"}, Date: now.AddDate(0, 0, -1), DueDate: startOfDay(now).AddDate(0, 0, -1)},
+		{Id: 5, Description: "Deploy notes", Boards: []string{"Work"}, Date: now, IsNote: true},
+	}}
+}
+
+func TestParseQuery(t *testing.T) {
+	now := time.Date(2026, 10, 14, 12, 0, 0, 0, time.Local)
+	tasks := queryTasks(now)
+	cases := map[string][]int{
+		``:                                   {1, 2, 3, 4, 5},
+		`board:Work status:pending`:          {1},
+		`board:work`:                         {1, 2, 5},
+		`board:"My Board"`:                   {3},
+		`board!=Work`:                        {3, 4},
+		`status:pending,done`:                {1, 2, 3, 4},
+		`priority>=2`:                        {1, 2},
+		`priority=1 is:task`:                 {3, 4},
+		`created<7d`:                         {2, 3, 4, 5},
+		`created>=7d`:                        {1},
+		`created<2026-10-13`:                 {1, 2},
+		`created:today`:                      {3, 5},
+		`due<=1d`:                            {3, 4},
+		`due:tomorrow`:                       {3},
+		`due:none`:                           {1, 2, 5},
+		`"deploy"`:                           {1, 2, 4, 5},
+		`deploy -board:Work`:                 {4},
+		`deploy not (board:Work or is:note)`: {4},
+		`milk OR api`:                        {1, 3},
+		`"pay deploy" and is:overdue`:        {4},
+		`is:starred or id=2`:                 {2, 3},
+		`text:"deploy w"`:                    {2},
+		`board:Work sort:-priority limit:2`:  {1, 2},
+		`sort:due,id`:                        {4, 3, 1, 2, 5},
+		`sort:-created limit:3`:              {3, 5, 4},
+	}
+	for input, expected := range cases {
+		q, err := ParseQuery(input, now)
+		if !assert.NoError(t, err, input) {
+			continue
+		}
+		var ids []int
+		for _, task := range q.Apply(tasks).Tasks {
+			ids = append(ids, task.Id)
+		}
+		assert.Equal(t, expected, ids, input)
+	}
+}
+
+func TestParseQuery_Errors(t *testing.T) {
+	now := time.Now()
+	for _, input := range []string{
+		`colour:red`,
+		`status:waiting`,
+		`priority>=high`,
+		`board<Work`,
+		`created<someday`,
+		`is:urgent`,
+		`(board:Work`,
+		`board:Work)`,
+		`"unterminated`,
+		`sort:size`,
+		`limit:0`,
+		`deploy or`,
+	} {
+		_, err := ParseQuery(input, now)
+		assert.Error(t, err, input)
+	}
+}
+
+func TestJoinArgs(t *testing.T) {
+	cases := map[string][]string{
+		`board:"My Board" status:pending`: {"board:My Board", "status:pending"},
+		`-board:"My Board"`:               {"-board:My Board"},
+		`"pay deploy" and is:overdue`:     {"pay deploy", "and", "is:overdue"},
+		`text:"deploy w"`:                 {`text:"deploy w"`},
+		`deploy -board:Work`:              {"deploy", "-board:Work"},
+	}
+	for expected, args := range cases {
+		assert.Equal(t, expected, JoinArgs(args), expected)
+	}
+
+	now := time.Date(2026, 10, 14, 12, 0, 0, 0, time.Local)
+	q, err := ParseQuery(JoinArgs([]string{"board:My Board"}), now)
+	assert.NoError(t, err)
+	found := q.Apply(queryTasks(now))
+	assert.Equal(t, 1, len(found.Tasks))
+	assert.Equal(t, 3, found.Tasks[0].Id)
+}
+
+func TestRepository_Query(t *testing.T) {
+	now := time.Now()
+	repository := newTestRepository(queryTasks(now).Tasks...)
+	q, err := ParseQuery(`board:Work status:pending priority>=2 created<14d "deploy"`, now)
+	assert.NoError(t, err)
+	found, err := repository.Query(q)
+	assert.NoError(t, err)
+	assert.Equal(t, 1, len(found.Tasks))
+	assert.Equal(t, 1, found.Tasks[0].Id)
+}
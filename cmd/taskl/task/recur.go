@@ -0,0 +1,221 @@
+package task
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+)
+
+// Recurrence kinds
+const (
+	RecurDaily    = "daily"
+	RecurWeekdays = "weekdays"
+	RecurWeekly   = "weekly"
+	RecurMonthly  = "monthly"
+	RecurEvery    = "every"
+)
+
+// Recurrence describes when next occurrence of completed task is due
+type Recurrence struct {
+	Kind string `json:"kind"`
+	// Days lists weekdays of weekly recurrence, weekday of the due date is used when empty
+	Days []time.Weekday `json:"days,omitempty"`
+	// Interval is number of days after completion of every recurrence
+	Interval int `json:"interval,omitempty"`
+	// Day is day of month monthly recurrence is anchored to, occurrences
+	// moved to the end of shorter months return to it afterwards
+	Day int `json:"day,omitempty"`
+}
+
+// IsZero reports whether recurrence is not set
+func (r Recurrence) IsZero() bool {
+	return r.Kind == ""
+}
+
+// String returns recurrence in the form accepted by ParseRecurrence
+func (r Recurrence) String() string {
+	switch r.Kind {
+	case RecurWeekly:
+		if len(r.Days) == 0 {
+			return RecurWeekly
+		}
+		names := make([]string, len(r.Days))
+		for idx, day := range r.Days {
+			names[idx] = strings.ToLower(day.String()[:3])
+		}
+		return RecurWeekly + " " + strings.Join(names, ",")
+	case RecurEvery:
+		return fmt.Sprintf("%s %dd", RecurEvery, r.Interval)
+	}
+	return r.Kind
+}
+
+// ParseRecurrence reads recurrence given as daily, weekdays, weekly,
+// weekly mon,thu, monthly or every 3d
+func ParseRecurrence(input string) (Recurrence, error) {
+	text := strings.ToLower(strings.Join(strings.Fields(input), " "))
+	switch text {
+	case RecurDaily, RecurWeekdays, RecurWeekly, RecurMonthly:
+		return Recurrence{Kind: text}, nil
+	}
+	if rest, ok := strings.CutPrefix(text, RecurWeekly+" "); ok {
+		var days []time.Weekday
+		seen := map[time.Weekday]bool{}
+		for _, name := range strings.Split(strings.ReplaceAll(rest, " ", ""), ",") {
+			day, ok := weekdays[name]
+			if !ok {
+				return Recurrence{}, fmt.Errorf("Unknown weekday: %s", name)
+			}
+			if !seen[day] {
+				seen[day] = true
+				days = append(days, day)
+			}
+		}
+		sort.Slice(days, func(i, j int) bool { return days[i] < days[j] })
+		return Recurrence{Kind: RecurWeekly, Days: days}, nil
+	}
+	if rest, ok := strings.CutPrefix(text, RecurEvery+" "); ok {
+		days, err := ParseDays(rest)
+		if err != nil || days < 1 {
+			return Recurrence{}, fmt.Errorf("Invalid recurrence interval: %s", rest)
+		}
+		return Recurrence{Kind: RecurEvery, Interval: days}, nil
+	}
+	return Recurrence{}, fmt.Errorf("Unsupported recurrence: %s, expected daily, weekdays, weekly [mon,thu], monthly or every 3d", input)
+}
+
+// Next returns due date of occurrence following the one completed at given
+// time. Scheduled kinds continue from previous due date and skip dates
+// which already passed, every N days is counted from completion.
+func (r Recurrence) Next(due, completed time.Time) time.Time {
+	today := startOfDay(completed)
+	if r.Kind == RecurEvery {
+		return today.AddDate(0, 0, r.Interval)
+	}
+	base := r.base(due, completed)
+	day := base.Day()
+	if r.Day > 0 {
+		day = r.Day
+	}
+	next := r.step(base, base.Weekday(), day)
+	for !next.After(today) {
+		next = r.step(next, base.Weekday(), day)
+	}
+	return next
+}
+
+// anchored returns copy of recurrence with monthly schedule anchored to
+// day of the due date, so later occurrences do not drift
+func (r Recurrence) anchored(due, completed time.Time) Recurrence {
+	if r.Kind == RecurMonthly && r.Day == 0 {
+		r.Day = r.base(due, completed).Day()
+	}
+	r.Days = append([]time.Weekday(nil), r.Days...)
+	return r
+}
+
+// base returns date schedule continues from, due date or day of completion
+func (r Recurrence) base(due, completed time.Time) time.Time {
+	if due.IsZero() {
+		return startOfDay(completed)
+	}
+	return startOfDay(due.In(completed.Location()))
+}
+
+// step returns first date of the schedule after given one, weekday and day
+// of month anchor weekly and monthly schedules
+func (r Recurrence) step(from time.Time, weekday time.Weekday, day int) time.Time {
+	switch r.Kind {
+	case RecurWeekdays:
+		next := from.AddDate(0, 0, 1)
+		for next.Weekday() == time.Saturday || next.Weekday() == time.Sunday {
+			next = next.AddDate(0, 0, 1)
+		}
+		return next
+	case RecurWeekly:
+		days := r.Days
+		if len(days) == 0 {
+			days = []time.Weekday{weekday}
+		}
+		next := from.AddDate(0, 0, 1)
+		for !containsWeekday(days, next.Weekday()) {
+			next = next.AddDate(0, 0, 1)
+		}
+		return next
+	case RecurMonthly:
+		return addMonth(from, day)
+	}
+	return from.AddDate(0, 0, 1)
+}
+
+// addMonth moves date to given day of next month, shorter months end on their last day
+func addMonth(from time.Time, day int) time.Time {
+	first := time.Date(from.Year(), from.Month()+1, 1, 0, 0, 0, 0, from.Location())
+	last := first.AddDate(0, 1, -1).Day()
+	return time.Date(first.Year(), first.Month(), min(day, last), 0, 0, 0, 0, from.Location())
+}
+
+func containsWeekday(days []time.Weekday, day time.Weekday) bool {
+	for _, candidate := range days {
+		if candidate == day {
+			return true
+		}
+	}
+	return false
+}
+
+// IsRecurring reports whether completing the task creates its next occurrence
+func (t Task) IsRecurring() bool {
+	return t.Recurrence != nil && !t.Recurrence.IsZero()
+}
+
+// nextOccurrence returns copy of completed recurring task due on next date,
+// dependencies, status and tracked time are not carried over
+func (t Task) nextOccurrence(at time.Time) Task {
+	recurrence := t.Recurrence.anchored(t.DueDate, at)
+	next := Task{
+		Date:        at,
+		Description: t.Description,
+		Boards:      append([]string(nil), t.Boards...),
+		Status:      StatusPending,
+		Priority:    t.Priority,
+		IsStarred:   t.IsStarred,
+		ParentId:    t.ParentId,
+		Tags:        append([]string(nil), t.Tags...),
+		Contexts:    append([]string(nil), t.Contexts...),
+		DueDate:     recurrence.Next(t.DueDate, at),
+		Estimate:    t.Estimate,
+		Recurrence:  &recurrence,
+	}
+	next.record(EventCreated, at, "recurs after %d", t.Id)
+	return next
+}
+
+// spawnOccurrences creates next occurrence of every completed recurring
+// task, completed instance keeps its history but no longer recurs
+func (rep *Repository) spawnOccurrences(tx Store, completed []int, at time.Time) error {
+	for _, id := range completed {
+		task, err := tx.Get(id)
+		if err != nil {
+			return err
+		}
+		if !task.IsRecurring() || task.Status != StatusDone {
+			continue
+		}
+		next := task.nextOccurrence(at)
+		if err := rep.assignId(tx, &next); err != nil {
+			return err
+		}
+		created, err := tx.Create(next)
+		if err != nil {
+			return err
+		}
+		task.Recurrence = nil
+		task.record(EventRecurred, at, "next occurrence %d due %s", created.Id, created.DueDate.Format(dueDateLayout))
+		if err := tx.Update(*task); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,150 @@
+package task
+
+import (
+	"github.com/stretchr/testify/assert"
+	"testing"
+	"time"
+)
+
+func TestParseRecurrence(t *testing.T) {
+	cases := map[string]Recurrence{
+		"daily":             {Kind: RecurDaily},
+		"Weekdays":          {Kind: RecurWeekdays},
+		"weekly":            {Kind: RecurWeekly},
+		"weekly thu,mon":    {Kind: RecurWeekly, Days: []time.Weekday{time.Monday, time.Thursday}},
+		"weekly mon, Mon":   {Kind: RecurWeekly, Days: []time.Weekday{time.Monday}},
+		"monthly":           {Kind: RecurMonthly},
+		"every 3d":          {Kind: RecurEvery, Interval: 3},
+		"every 2 weeks":     {Kind: RecurEvery, Interval: 14},
+		"  every   1 day  ": {Kind: RecurEvery, Interval: 1},
+	}
+	for input, expected := range cases {
+		recurrence, err := ParseRecurrence(input)
+		assert.NoError(t, err, input)
+		assert.Equal(t, expected, recurrence, input)
+	}
+
+	for _, input := range []string{"", "yearly", "weekly someday", "every 0d", "every day"} {
+		_, err := ParseRecurrence(input)
+		assert.Error(t, err, input)
+	}
+}
+
+func TestRecurrence_String(t *testing.T) {
+	for _, input := range []string{"daily", "weekdays", "weekly", "weekly mon,thu", "monthly", "every 3d"} {
+		recurrence, err := ParseRecurrence(input)
+		assert.NoError(t, err)
+		assert.Equal(t, input, recurrence.String())
+	}
+}
+
+func TestRecurrence_Next(t *testing.T) {
+	date := func(month time.Month, day int) time.Time {
+		return time.Date(2026, month, day, 0, 0, 0, 0, time.Local)
+	}
+	// Wednesday afternoon
+	wednesday := time.Date(2026, 10, 14, 15, 30, 0, 0, time.Local)
+	friday := time.Date(2026, 10, 16, 9, 0, 0, 0, time.Local)
+	cases := []struct {
+		input     string
+		due       time.Time
+		completed time.Time
+		expected  time.Time
+	}{
+		{"daily", time.Time{}, wednesday, date(10, 15)},
+		{"daily", date(10, 10), wednesday, date(10, 15)},
+		{"daily", date(10, 20), wednesday, date(10, 21)},
+		{"weekdays", date(10, 16), friday, date(10, 19)},
+		{"weekdays", date(10, 14), wednesday, date(10, 15)},
+		{"weekly", date(10, 12), wednesday, date(10, 19)},
+		{"weekly mon,thu", date(10, 12), wednesday, date(10, 15)},
+		{"weekly mon,thu", time.Time{}, friday, date(10, 19)},
+		{"monthly", date(1, 31), time.Date(2026, 1, 31, 8, 0, 0, 0, time.Local), date(2, 28)},
+		{"monthly", date(9, 14), wednesday, date(11, 14)},
+		{"every 3d", date(10, 1), wednesday, date(10, 17)},
+	}
+	for _, c := range cases {
+		recurrence, err := ParseRecurrence(c.input)
+		assert.NoError(t, err)
+		next := recurrence.Next(c.due, c.completed)
+		assert.True(t, c.expected.Equal(next), "%s due %v: expected %v got %v", c.input, c.due, c.expected, next)
+	}
+}
+
+func TestRecurrence_MonthlyKeepsMonthEnd(t *testing.T) {
+	task := Task{Id: 1, DueDate: time.Date(2026, 1, 31, 0, 0, 0, 0, time.Local), Recurrence: &Recurrence{Kind: RecurMonthly}}
+	for _, expected := range []time.Time{
+		time.Date(2026, 2, 28, 0, 0, 0, 0, time.Local),
+		time.Date(2026, 3, 31, 0, 0, 0, 0, time.Local),
+		time.Date(2026, 4, 30, 0, 0, 0, 0, time.Local),
+		time.Date(2026, 5, 31, 0, 0, 0, 0, time.Local),
+	} {
+		// every occurrence is completed on its due date
+		task = task.nextOccurrence(task.DueDate.Add(9 * time.Hour))
+		assert.True(t, expected.Equal(task.DueDate), "expected %v got %v", expected, task.DueDate)
+		assert.Equal(t, 31, task.Recurrence.Day)
+	}
+}
+
+func TestRepository_CompleteSpawnsOccurrence(t *testing.T) {
+	assert := assert.New(t)
+	repository := newTestRepository()
+	daily := Recurrence{Kind: RecurDaily}
+	created, err := repository.Create(Task{Description: "Stand-up", Boards: []string{"Work"}, Tags: []string{"team"}, Recurrence: &daily})
+	assert.NoError(err)
+	assert.NoError(repository.Start(created.Id))
+
+	assert.NoError(repository.Complete(created.Id))
+
+	tl, err := repository.GetAll()
+	assert.NoError(err)
+	assert.Len(tl.Tasks, 2)
+	completed, err := repository.Get(created.Id)
+	assert.NoError(err)
+	assert.Equal(StatusDone, completed.Status)
+	assert.False(completed.IsRecurring())
+	assert.Equal(EventRecurred, completed.History[len(completed.History)-1].Type)
+
+	next := tl.Tasks[1]
+	assert.NotEqual(created.Id, next.Id)
+	assert.Equal("Stand-up", next.Description)
+	assert.Equal([]string{"Work"}, next.Boards)
+	assert.Equal([]string{"team"}, next.Tags)
+	assert.Equal(StatusPending, next.Status)
+	assert.Zero(next.PassedTime)
+	assert.Equal(&daily, next.Recurrence)
+	assert.True(startOfDay(time.Now()).AddDate(0, 0, 1).Equal(next.DueDate))
+
+	// reopening and completing again does not duplicate the occurrence
+	assert.NoError(repository.Reopen(created.Id))
+	assert.NoError(repository.Complete(created.Id))
+	tl, err = repository.GetAll()
+	assert.NoError(err)
+	assert.Len(tl.Tasks, 2)
+}
+
+func TestRepository_CancelDoesNotRecur(t *testing.T) {
+	repository := newTestRepository(
+		Task{Id: 1, Status: StatusPending, Recurrence: &Recurrence{Kind: RecurWeekly}},
+	)
+
+	_, err := repository.TransitionAll([]int{1}, StatusCancelled, "")
+	assert.NoError(t, err)
+	tl, err := repository.GetAll()
+	assert.NoError(t, err)
+	assert.Len(t, tl.Tasks, 1)
+}
+
+func TestRepository_EditRecurrence(t *testing.T) {
+	repository := newTestRepository(Task{Id: 1, Status: StatusPending})
+	monthly := Recurrence{Kind: RecurMonthly}
+
+	edited, err := repository.Edit(1, Edit{Recurrence: &monthly})
+	assert.NoError(t, err)
+	assert.Equal(t, &monthly, edited.Recurrence)
+	assert.Equal(t, "repeats monthly", edited.History[len(edited.History)-1].Details)
+
+	edited, err = repository.Edit(1, Edit{Recurrence: &Recurrence{}})
+	assert.NoError(t, err)
+	assert.Nil(t, edited.Recurrence)
+}
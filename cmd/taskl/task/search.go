@@ -0,0 +1,147 @@
+package task
+
+import (
+	"github.com/pkg/errors"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+)
+
+// Matcher looks for search terms in task text ignoring case, all terms have to match
+type Matcher struct {
+	patterns []*regexp.Regexp
+}
+
+// NewMatcher creates matcher of plain substrings or, when regex is set, regular expressions
+func NewMatcher(terms []string, regex bool) (*Matcher, error) {
+	m := &Matcher{}
+	for _, term := range terms {
+		if term == "" {
+			continue
+		}
+		if !regex {
+			term = regexp.QuoteMeta(term)
+		}
+		pattern, err := regexp.Compile("(?i)" + term)
+		if err != nil {
+			return nil, errors.WithMessagef(err, "Invalid search pattern: %s", term)
+		}
+		m.patterns = append(m.patterns, pattern)
+	}
+	return m, nil
+}
+
+// MatchString reports whether text contains all terms
+func (m *Matcher) MatchString(text string) bool {
+	for _, pattern := range m.patterns {
+		if !pattern.MatchString(text) {
+			return false
+		}
+	}
+	return true
+}
+
+// Match reports whether task description, tags or contexts contain all terms
+func (m *Matcher) Match(t Task) bool {
+	return m.MatchString(strings.Join(append([]string{t.Description}, t.Labels()...), " "))
+}
+
+// Highlight wraps every fragment of text matching any term with mark
+func (m *Matcher) Highlight(text string, mark func(string) string) string {
+	var ranges [][]int
+	for _, pattern := range m.patterns {
+		for _, r := range pattern.FindAllStringIndex(text, -1) {
+			if r[0] < r[1] {
+				ranges = append(ranges, r)
+			}
+		}
+	}
+	if len(ranges) == 0 {
+		return text
+	}
+	sort.Slice(ranges, func(i, j int) bool { return ranges[i][0] < ranges[j][0] })
+
+	var out strings.Builder
+	last := 0
+	for idx := 0; idx < len(ranges); {
+		start, end := ranges[idx][0], ranges[idx][1]
+		for idx++; idx < len(ranges) && ranges[idx][0] <= end; idx++ {
+			if ranges[idx][1] > end {
+				end = ranges[idx][1]
+			}
+		}
+		out.WriteString(text[last:start])
+		out.WriteString(mark(text[start:end]))
+		last = end
+	}
+	out.WriteString(text[last:])
+	return out.String()
+}
+
+// Filter selects tasks by their fields, zero fields match every task
+type Filter struct {
+	Matcher  *Matcher
+	Statuses []Status
+	Board    string
+	// CreatedAfter is inclusive, CreatedBefore exclusive bound of task creation date
+	CreatedAfter  time.Time
+	CreatedBefore time.Time
+	MinPriority   int
+}
+
+// Match reports whether task passes all filter conditions
+func (f Filter) Match(t Task) bool {
+	if f.Matcher != nil && !f.Matcher.Match(t) {
+		return false
+	}
+	if len(f.Statuses) > 0 {
+		found := false
+		for _, status := range f.Statuses {
+			found = found || (!t.IsNote && statusOf(t) == status)
+		}
+		if !found {
+			return false
+		}
+	}
+	if f.Board != "" && !t.OnBoard(f.Board) {
+		return false
+	}
+	if !f.CreatedAfter.IsZero() && t.Date.Before(f.CreatedAfter) {
+		return false
+	}
+	if !f.CreatedBefore.IsZero() && !t.Date.Before(f.CreatedBefore) {
+		return false
+	}
+	return f.MinPriority == 0 || (!t.IsNote && t.PriorityLevel() >= f.MinPriority)
+}
+
+// Find returns tasks matching filter
+func (rep *Repository) Find(filter Filter) (TaskList, error) {
+	tl, err := lookup(rep.store, filter.Board, filter.Statuses)
+	if err != nil {
+		return TaskList{}, err
+	}
+	return tl.Filter(filter.Match), nil
+}
+
+// ParseDate reads date given as 2026-11-01, today, yesterday or span in
+// the past like 7d or 2w, returned date is midnight of that day
+func ParseDate(input string, now time.Time) (time.Time, error) {
+	text := strings.ToLower(strings.TrimSpace(input))
+	today := startOfDay(now)
+	switch text {
+	case "today":
+		return today, nil
+	case "yesterday":
+		return today.AddDate(0, 0, -1), nil
+	}
+	if date, err := time.ParseInLocation(dueDateLayout, text, now.Location()); err == nil {
+		return date, nil
+	}
+	days, err := ParseDays(strings.TrimSuffix(text, " ago"))
+	if err != nil {
+		return time.Time{}, errors.Errorf("Unsupported date: %s, expected e.g. 2026-11-01, yesterday or 7d", input)
+	}
+	return today.AddDate(0, 0, -days), nil
+}
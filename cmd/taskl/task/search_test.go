@@ -0,0 +1,78 @@
+package task
+
+import (
+	"github.com/stretchr/testify/assert"
+	"testing"
+	"time"
+)
+
+func TestMatcher(t *testing.T) {
+	assert := assert.New(t)
+	matcher, err := NewMatcher([]string{"deploy", "PROD"}, false)
+	assert.NoError(err)
+	assert.True(matcher.MatchString("Deploy to production"))
+	assert.False(matcher.MatchString("Deploy to staging"))
+
+	mark := func(s string) string { return "[" + s + "]" }
+	assert.Equal("[Deploy] to [prod]uction", matcher.Highlight("Deploy to production", mark))
+
+	matcher, err = NewMatcher([]string{"a.c"}, false)
+	assert.NoError(err)
+	assert.False(matcher.MatchString("abc"))
+	assert.True(matcher.MatchString("a.c"))
+
+	matcher, err = NewMatcher([]string{`v\d+`, "v1"}, true)
+	assert.NoError(err)
+	assert.True(matcher.MatchString("Release v12"))
+	assert.Equal("Release [v12]", matcher.Highlight("Release v12", mark))
+
+	_, err = NewMatcher([]string{"(unclosed"}, true)
+	assert.Error(err)
+}
+
+func TestRepository_Find(t *testing.T) {
+	assert := assert.New(t)
+	now := time.Date(2026, 10, 14, 12, 0, 0, 0, time.Local)
+	repository := newTestRepository(
+		Task{Id: 1, Description: "Deploy api", Boards: []string{"Work"}, Date: now.AddDate(0, 0, -10), Priority: PriorityHigh},
+		Task{Id: 2, Description: "Deploy web", Boards: []string{"Work"}, Date: now.AddDate(0, 0, -1), Status: StatusDone},
+		Task{Id: 3, Description: "Buy deployment book", Boards: []string{"Home"}, Date: now},
+		Task{Id: 4, Description: "Deploy notes", Boards: []string{"Work"}, Date: now, IsNote: true},
+	)
+	matcher, err := NewMatcher([]string{"deploy"}, false)
+	assert.NoError(err)
+
+	ids := func(filter Filter) []int {
+		found, err := repository.Find(filter)
+		assert.NoError(err)
+		var ids []int
+		for _, t := range found.Tasks {
+			ids = append(ids, t.Id)
+		}
+		return ids
+	}
+	assert.Equal([]int{1, 2, 3, 4}, ids(Filter{Matcher: matcher}))
+	assert.Equal([]int{1, 2, 4}, ids(Filter{Matcher: matcher, Board: "Work"}))
+	assert.Equal([]int{1, 3}, ids(Filter{Matcher: matcher, Statuses: []Status{StatusPending}}))
+	assert.Equal([]int{2, 3, 4}, ids(Filter{Matcher: matcher, CreatedAfter: startOfDay(now).AddDate(0, 0, -1)}))
+	assert.Equal([]int{1, 2}, ids(Filter{Matcher: matcher, CreatedBefore: startOfDay(now)}))
+	assert.Equal([]int{1}, ids(Filter{Matcher: matcher, MinPriority: PriorityMedium}))
+}
+
+func TestParseDate(t *testing.T) {
+	assert := assert.New(t)
+	now := time.Date(2026, 10, 14, 12, 0, 0, 0, time.Local)
+	for input, expected := range map[string]time.Time{
+		"2026-10-01":  time.Date(2026, 10, 1, 0, 0, 0, 0, time.Local),
+		"today":       time.Date(2026, 10, 14, 0, 0, 0, 0, time.Local),
+		"yesterday":   time.Date(2026, 10, 13, 0, 0, 0, 0, time.Local),
+		"7d":          time.Date(2026, 10, 7, 0, 0, 0, 0, time.Local),
+		"2 weeks ago": time.Date(2026, 9, 30, 0, 0, 0, 0, time.Local),
+	} {
+		date, err := ParseDate(input, now)
+		assert.NoError(err, input)
+		assert.True(expected.Equal(date), "%s: expected %v got %v", input, expected, date)
+	}
+	_, err := ParseDate("last summer", now)
+	assert.Error(err)
+}
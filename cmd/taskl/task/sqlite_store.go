@@ -0,0 +1,342 @@
+package task
+
+import (
+	"database/sql"
+	"encoding/json"
+	"github.com/pkg/errors"
+	"os"
+	"path/filepath"
+	"strings"
+
+	_ "modernc.org/sqlite"
+)
+
+const (
+	sqliteFilename        = "taskl.db"
+	sqliteArchiveFilename = "archive.db"
+)
+
+// migrations are applied in order, position in slice + 1 is schema version
+var migrations = []string{
+	`CREATE TABLE tasks (
+		id     INTEGER PRIMARY KEY,
+		status TEXT NOT NULL,
+		data   TEXT NOT NULL
+	);
+	CREATE INDEX tasks_status_idx ON tasks(status);`,
+	`CREATE TABLE task_boards (
+		task_id INTEGER NOT NULL REFERENCES tasks(id) ON DELETE CASCADE,
+		board   TEXT NOT NULL,
+		PRIMARY KEY (task_id, board)
+	);
+	CREATE INDEX task_boards_board_idx ON task_boards(board);`,
+}
+
+// SQLiteStore keeps tasks in embedded sqlite database.
+// Full task is stored as json document, columns are kept only for indexed lookups.
+type SQLiteStore struct {
+	Path string
+	db   *sql.DB
+}
+
+// SQLiteExists reports whether sqlite database was already created in storagePath
+func SQLiteExists(storagePath string) bool {
+	_, err := os.Stat(filepath.Join(storagePath, sqliteFilename))
+	return err == nil
+}
+
+func NewSQLiteStore(storagePath string) (*SQLiteStore, error) {
+	return openSQLite(storagePath, sqliteFilename)
+}
+
+// NewSQLiteArchive opens database of archived tasks located in storagePath
+func NewSQLiteArchive(storagePath string) (*SQLiteStore, error) {
+	return openSQLite(storagePath, sqliteArchiveFilename)
+}
+
+func openSQLite(storagePath, filename string) (*SQLiteStore, error) {
+	if err := os.MkdirAll(storagePath, os.ModePerm); err != nil {
+		return nil, errors.WithMessagef(err, "Failed to create storage dir, loc: %v", storagePath)
+	}
+	path := filepath.Join(storagePath, filename)
+	db, err := sql.Open("sqlite", path+"?_pragma=foreign_keys(1)&_pragma=busy_timeout(5000)&_txlock=immediate")
+	if err != nil {
+		return nil, errors.WithMessagef(err, "Failed to open database, loc: %v", path)
+	}
+	s := &SQLiteStore{Path: path, db: db}
+	if err := s.migrate(); err != nil {
+		db.Close()
+		return nil, err
+	}
+	return s, nil
+}
+
+func (s *SQLiteStore) Close() error {
+	return s.db.Close()
+}
+
+// SchemaVersion returns number of applied migrations
+func (s *SQLiteStore) SchemaVersion() (int, error) {
+	var version int
+	err := s.db.QueryRow(`SELECT COALESCE(MAX(version), 0) FROM schema_migrations`).Scan(&version)
+	return version, err
+}
+
+func (s *SQLiteStore) migrate() error {
+	if _, err := s.db.Exec(`CREATE TABLE IF NOT EXISTS schema_migrations (version INTEGER PRIMARY KEY)`); err != nil {
+		return errors.WithMessage(err, "SQLiteStore: Failed to create migrations table")
+	}
+	current, err := s.SchemaVersion()
+	if err != nil {
+		return errors.WithMessage(err, "SQLiteStore: Failed to read schema version")
+	}
+	for idx := current; idx < len(migrations); idx++ {
+		version := idx + 1
+		Log("SQLiteStore: applying migration: %d", version)
+		err := s.inTx(func(tx *sql.Tx) error {
+			//other process may have applied the migration since version was read
+			var applied int
+			if err := tx.QueryRow(`SELECT COUNT(*) FROM schema_migrations WHERE version = ?`, version).Scan(&applied); err != nil || applied > 0 {
+				return err
+			}
+			if _, err := tx.Exec(migrations[idx]); err != nil {
+				return err
+			}
+			_, err := tx.Exec(`INSERT INTO schema_migrations(version) VALUES (?)`, version)
+			return err
+		})
+		if err != nil {
+			return errors.WithMessagef(err, "SQLiteStore: Failed to apply migration: %d", version)
+		}
+	}
+	return nil
+}
+
+func (s *SQLiteStore) inTx(fn func(tx *sql.Tx) error) error {
+	tx, err := s.db.Begin()
+	if err != nil {
+		return err
+	}
+	if err := fn(tx); err != nil {
+		tx.Rollback()
+		return err
+	}
+	return tx.Commit()
+}
+
+func (s *SQLiteStore) Get(id int) (*Task, error) {
+	return sqliteTx{s.db}.Get(id)
+}
+
+func (s *SQLiteStore) List() (*TaskList, error) {
+	return sqliteTx{s.db}.List()
+}
+
+// ListByBoard returns tasks attached to given board, tasks without boards
+// belong to the default one
+func (s *SQLiteStore) ListByBoard(board string) (*TaskList, error) {
+	return sqliteTx{s.db}.query(`SELECT t.data FROM tasks t
+		WHERE EXISTS (SELECT 1 FROM task_boards b WHERE b.task_id = t.id AND b.board = ?)
+		OR (? = ? AND NOT EXISTS (SELECT 1 FROM task_boards b WHERE b.task_id = t.id))
+		ORDER BY t.id`, board, board, DefaultBoard)
+}
+
+// ListByStatus returns tasks in any of given statuses
+func (s *SQLiteStore) ListByStatus(statuses ...Status) (*TaskList, error) {
+	if len(statuses) == 0 {
+		return &TaskList{}, nil
+	}
+	args := make([]interface{}, len(statuses))
+	for idx, status := range statuses {
+		args[idx] = string(status)
+	}
+	placeholders := strings.TrimSuffix(strings.Repeat("?, ", len(statuses)), ", ")
+	return sqliteTx{s.db}.query(`SELECT data FROM tasks WHERE status IN (`+placeholders+`) ORDER BY id`, args...)
+}
+
+func (s *SQLiteStore) Create(t Task) (*Task, error) {
+	var created *Task
+	err := s.Transaction(func(tx Store) error {
+		var err error
+		created, err = tx.Create(t)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+	return created, nil
+}
+
+func (s *SQLiteStore) Update(t Task) error {
+	return s.Transaction(func(tx Store) error {
+		return tx.Update(t)
+	})
+}
+
+func (s *SQLiteStore) Delete(id int) error {
+	return sqliteTx{s.db}.Delete(id)
+}
+
+func (s *SQLiteStore) NextId() (int, error) {
+	return sqliteTx{s.db}.NextId()
+}
+
+func (s *SQLiteStore) Transaction(fn func(tx Store) error) error {
+	return s.inTx(func(tx *sql.Tx) error {
+		return fn(sqliteTx{tx})
+	})
+}
+
+// Import stores all tasks in single transaction keeping their ids,
+// tasks with ids already present in database are skipped
+func (s *SQLiteStore) Import(tasks []Task) (imported, skipped []int, err error) {
+	err = s.inTx(func(tx *sql.Tx) error {
+		q := sqliteTx{tx}
+		for _, t := range tasks {
+			exists, err := q.exists(t.Id)
+			if err != nil {
+				return err
+			}
+			if exists || t.Id < 1 {
+				skipped = append(skipped, t.Id)
+				continue
+			}
+			if err := q.insert(t); err != nil {
+				return errors.WithMessagef(err, "Failed to import task: %d", t.Id)
+			}
+			imported = append(imported, t.Id)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, nil, errors.WithMessage(err, "SQLiteStore: Import failed")
+	}
+	return imported, skipped, nil
+}
+
+type querier interface {
+	Exec(query string, args ...interface{}) (sql.Result, error)
+	Query(query string, args ...interface{}) (*sql.Rows, error)
+	QueryRow(query string, args ...interface{}) *sql.Row
+}
+
+// sqliteTx implements Store operations on top of database or running transaction
+type sqliteTx struct {
+	q querier
+}
+
+func (s sqliteTx) Get(id int) (*Task, error) {
+	var data string
+	err := s.q.QueryRow(`SELECT data FROM tasks WHERE id = ?`, id).Scan(&data)
+	if err == sql.ErrNoRows {
+		return nil, notFound(id)
+	}
+	if err != nil {
+		return nil, errors.WithMessagef(err, "SQLiteStore: Failed to read task: %d", id)
+	}
+	var t Task
+	if err := json.Unmarshal([]byte(data), &t); err != nil {
+		return nil, errors.WithMessagef(err, "SQLiteStore: Failed to unmarshal task: %d", id)
+	}
+	return &t, nil
+}
+
+func (s sqliteTx) List() (*TaskList, error) {
+	return s.query(`SELECT data FROM tasks ORDER BY id`)
+}
+
+func (s sqliteTx) query(query string, args ...interface{}) (*TaskList, error) {
+	rows, err := s.q.Query(query, args...)
+	if err != nil {
+		return nil, errors.WithMessage(err, "SQLiteStore: Failed to query tasks")
+	}
+	defer rows.Close()
+
+	tl := &TaskList{}
+	for rows.Next() {
+		var data string
+		if err := rows.Scan(&data); err != nil {
+			return nil, err
+		}
+		var t Task
+		if err := json.Unmarshal([]byte(data), &t); err != nil {
+			return nil, errors.WithMessage(err, "SQLiteStore: Failed to unmarshal task")
+		}
+		tl.Tasks = append(tl.Tasks, t)
+	}
+	return tl, rows.Err()
+}
+
+func (s sqliteTx) Create(t Task) (*Task, error) {
+	if t.Id < 1 {
+		id, err := s.NextId()
+		if err != nil {
+			return nil, err
+		}
+		t.Id = id
+	} else if exists, err := s.exists(t.Id); err != nil {
+		return nil, err
+	} else if exists {
+		return nil, alreadyExists(t.Id)
+	}
+	if err := s.insert(t); err != nil {
+		return nil, errors.WithMessage(err, "SQLiteStore: Failed to create task")
+	}
+	return &t, nil
+}
+
+func (s sqliteTx) Update(t Task) error {
+	if exists, err := s.exists(t.Id); err != nil {
+		return err
+	} else if !exists {
+		return notFound(t.Id)
+	}
+	if _, err := s.q.Exec(`DELETE FROM tasks WHERE id = ?`, t.Id); err != nil {
+		return err
+	}
+	return s.insert(t)
+}
+
+func (s sqliteTx) Delete(id int) error {
+	res, err := s.q.Exec(`DELETE FROM tasks WHERE id = ?`, id)
+	if err != nil {
+		return errors.WithMessagef(err, "SQLiteStore: Failed to delete task: %d", id)
+	}
+	if n, err := res.RowsAffected(); err == nil && n == 0 {
+		return notFound(id)
+	}
+	return nil
+}
+
+func (s sqliteTx) NextId() (int, error) {
+	var id int
+	err := s.q.QueryRow(`SELECT COALESCE(MAX(id), 0) + 1 FROM tasks`).Scan(&id)
+	return id, err
+}
+
+// Transaction runs fn in already opened transaction
+func (s sqliteTx) Transaction(fn func(tx Store) error) error {
+	return fn(s)
+}
+
+func (s sqliteTx) exists(id int) (bool, error) {
+	var count int
+	err := s.q.QueryRow(`SELECT COUNT(*) FROM tasks WHERE id = ?`, id).Scan(&count)
+	return count > 0, err
+}
+
+func (s sqliteTx) insert(t Task) error {
+	data, err := json.Marshal(t)
+	if err != nil {
+		return err
+	}
+	if _, err := s.q.Exec(`INSERT INTO tasks(id, status, data) VALUES (?, ?, ?)`, t.Id, string(statusOf(t)), string(data)); err != nil {
+		return err
+	}
+	for _, board := range t.Boards {
+		if _, err := s.q.Exec(`INSERT OR IGNORE INTO task_boards(task_id, board) VALUES (?, ?)`, t.Id, board); err != nil {
+			return err
+		}
+	}
+	return nil
+}
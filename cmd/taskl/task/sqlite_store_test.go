@@ -0,0 +1,191 @@
+package task
+
+import (
+	"errors"
+	"github.com/stretchr/testify/assert"
+	"os"
+	"os/exec"
+	"strconv"
+	"testing"
+)
+
+func TestSQLiteStore_CRUD(t *testing.T) {
+	f, err := os.MkdirTemp("", "")
+	assert.NoError(t, err)
+	defer os.RemoveAll(f)
+
+	store, err := NewSQLiteStore(f)
+	assert.NoError(t, err)
+	defer store.Close()
+	repository := NewRepositoryWithStore(store)
+
+	first, err := repository.Create(Task{Description: "First", Boards: []string{"Work"}})
+	assert.NoError(t, err)
+	assert.Equal(t, 1, first.Id)
+	second, err := repository.Create(Task{Description: "Second", Boards: []string{"Home"}})
+	assert.NoError(t, err)
+	assert.Equal(t, 2, second.Id)
+
+	assert.NoError(t, repository.Complete(first.Id))
+	loaded, err := repository.Get(first.Id)
+	assert.NoError(t, err)
+	assert.Equal(t, StatusDone, loaded.Status)
+	assert.Equal(t, "First", loaded.Description)
+
+	byBoard, err := store.ListByBoard("Home")
+	assert.NoError(t, err)
+	assert.Equal(t, 1, len(byBoard.Tasks))
+	assert.Equal(t, second.Id, byBoard.Tasks[0].Id)
+
+	byStatus, err := store.ListByStatus("done")
+	assert.NoError(t, err)
+	assert.Equal(t, 1, len(byStatus.Tasks))
+	assert.Equal(t, first.Id, byStatus.Tasks[0].Id)
+
+	assert.NoError(t, repository.Delete(first.Id))
+	_, err = repository.Get(first.Id)
+	assert.True(t, errors.Is(err, ErrTaskNotFound))
+
+	byBoard, err = store.ListByBoard("Work")
+	assert.NoError(t, err)
+	assert.Equal(t, 0, len(byBoard.Tasks))
+}
+
+func TestSQLiteStore_IndexedLookups(t *testing.T) {
+	f, err := os.MkdirTemp("", "")
+	assert.NoError(t, err)
+	defer os.RemoveAll(f)
+
+	store, err := NewSQLiteStore(f)
+	assert.NoError(t, err)
+	defer store.Close()
+	archive, err := NewSQLiteArchive(f)
+	assert.NoError(t, err)
+	defer archive.Close()
+	repository := NewRepositoryWithArchive(store, archive)
+
+	for _, task := range []Task{
+		{Description: "Unassigned", Status: StatusDone},
+		{Description: "Work", Boards: []string{"Work"}, Status: StatusCancelled},
+		{Description: "Default", Boards: []string{DefaultBoard}, Status: StatusPending},
+		{Description: "Note", Boards: []string{"Work"}, IsNote: true},
+	} {
+		_, err := repository.Create(task)
+		assert.NoError(t, err)
+	}
+
+	byBoard, err := store.ListByBoard(DefaultBoard)
+	assert.NoError(t, err)
+	assert.Equal(t, []int{1, 3}, taskIds(byBoard.Tasks))
+	byStatus, err := store.ListByStatus(StatusDone, StatusCancelled)
+	assert.NoError(t, err)
+	assert.Equal(t, []int{1, 2}, taskIds(byStatus.Tasks))
+
+	boardTasks, err := repository.BoardTasks("Work")
+	assert.NoError(t, err)
+	assert.Equal(t, []int{2, 4}, taskIds(boardTasks))
+	found, err := repository.Find(Filter{Board: "Work", Statuses: []Status{StatusCancelled}})
+	assert.NoError(t, err)
+	assert.Equal(t, []int{2}, taskIds(found.Tasks))
+
+	_, err = repository.Clear("Work")
+	assert.NoError(t, err)
+	left, err := store.List()
+	assert.NoError(t, err)
+	assert.Equal(t, []int{1, 3, 4}, taskIds(left.Tasks))
+}
+
+func TestSQLiteStore_MigrationsAreAppliedOnce(t *testing.T) {
+	f, err := os.MkdirTemp("", "")
+	assert.NoError(t, err)
+	defer os.RemoveAll(f)
+
+	store, err := NewSQLiteStore(f)
+	assert.NoError(t, err)
+	_, err = store.Create(Task{Description: "Survives reopen"})
+	assert.NoError(t, err)
+	store.Close()
+
+	store, err = NewSQLiteStore(f)
+	assert.NoError(t, err)
+	defer store.Close()
+	version, err := store.SchemaVersion()
+	assert.NoError(t, err)
+	assert.Equal(t, len(migrations), version)
+
+	all, err := store.List()
+	assert.NoError(t, err)
+	assert.Equal(t, 1, len(all.Tasks))
+}
+
+func TestSQLiteStore_Import(t *testing.T) {
+	f, err := os.MkdirTemp("", "")
+	assert.NoError(t, err)
+	defer os.RemoveAll(f)
+
+	store, err := NewSQLiteStore(f)
+	assert.NoError(t, err)
+	defer store.Close()
+	_, err = store.Create(Task{Id: 2, Description: "Already there"})
+	assert.NoError(t, err)
+
+	imported, skipped, err := store.Import([]Task{
+		{Id: 1, Description: "One", Boards: []string{"Work"}},
+		{Id: 2, Description: "Two"},
+		{Id: 7, Description: "Seven", Status: StatusDone},
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, []int{1, 7}, imported)
+	assert.Equal(t, []int{2}, skipped)
+
+	next, err := store.NextId()
+	assert.NoError(t, err)
+	assert.Equal(t, 8, next)
+	loaded, err := store.Get(2)
+	assert.NoError(t, err)
+	assert.Equal(t, "Already there", loaded.Description)
+}
+
+// TestSQLiteHelperProcess creates tasks when started as separate process
+// by TestSQLiteStore_ConcurrentCreateKeepsAllTasks
+func TestSQLiteHelperProcess(t *testing.T) {
+	dir := os.Getenv("TASKL_SQLITE_HELPER_DIR")
+	if dir == "" {
+		t.Skip("run only as helper process")
+	}
+	store, err := NewSQLiteStore(dir)
+	assert.NoError(t, err)
+	defer store.Close()
+	repository := NewRepositoryWithStore(store)
+	for i := 0; i < 5; i++ {
+		_, err := repository.Create(Task{Description: "Task " + strconv.Itoa(i)})
+		assert.NoError(t, err)
+	}
+}
+
+func TestSQLiteStore_ConcurrentCreateKeepsAllTasks(t *testing.T) {
+	dir := t.TempDir()
+	const writers = 20
+	var processes []*exec.Cmd
+	for i := 0; i < writers; i++ {
+		cmd := exec.Command(os.Args[0], "-test.run=^TestSQLiteHelperProcess$")
+		cmd.Env = append(os.Environ(), "TASKL_SQLITE_HELPER_DIR="+dir)
+		assert.NoError(t, cmd.Start())
+		processes = append(processes, cmd)
+	}
+	for _, cmd := range processes {
+		assert.NoError(t, cmd.Wait(), "helper process failed to create tasks")
+	}
+
+	store, err := NewSQLiteStore(dir)
+	assert.NoError(t, err)
+	defer store.Close()
+	loaded, err := store.List()
+	assert.NoError(t, err)
+	assert.Equal(t, writers*5, len(loaded.Tasks))
+	ids := map[int]bool{}
+	for _, task := range loaded.Tasks {
+		ids[task.Id] = true
+	}
+	assert.Equal(t, writers*5, len(ids))
+}
@@ -0,0 +1,125 @@
+package task
+
+import (
+	"encoding/json"
+	"github.com/pkg/errors"
+	"strings"
+	"time"
+)
+
+type Status string
+
+const (
+	StatusPending    Status = "pending"
+	StatusInProgress Status = "in-progress"
+	StatusPaused     Status = "paused"
+	StatusBlocked    Status = "blocked"
+	StatusDone       Status = "done"
+	StatusCancelled  Status = "cancelled"
+)
+
+// ErrIllegalTransition is returned when task can not be moved to requested status
+var ErrIllegalTransition = errors.New("illegal status transition")
+
+// allowedTransitions lists statuses reachable from given status
+var allowedTransitions = map[Status][]Status{
+	StatusPending:    {StatusInProgress, StatusBlocked, StatusDone, StatusCancelled},
+	StatusInProgress: {StatusPaused, StatusBlocked, StatusDone, StatusCancelled, StatusPending},
+	StatusPaused:     {StatusInProgress, StatusBlocked, StatusDone, StatusCancelled, StatusPending},
+	StatusBlocked:    {StatusPending, StatusInProgress, StatusCancelled},
+	StatusDone:       {StatusPending},
+	StatusCancelled:  {StatusPending},
+}
+
+// Transition records single status change of the task
+type Transition struct {
+	From Status    `json:"from"`
+	To   Status    `json:"to"`
+	At   time.Time `json:"at"`
+}
+
+// IsOpen reports whether task with given status still needs some work
+func (s Status) IsOpen() bool {
+	return s != StatusDone && s != StatusCancelled
+}
+
+// ParseStatus converts status name into Status
+func ParseStatus(name string) (Status, error) {
+	status := Status(strings.ToLower(strings.TrimSpace(name)))
+	if _, ok := allowedTransitions[status]; !ok {
+		return "", errors.Errorf("Unknown status: %s", name)
+	}
+	return status, nil
+}
+
+// CanTransition reports whether status can be changed to given one
+func (s Status) CanTransition(to Status) bool {
+	for _, allowed := range allowedTransitions[s] {
+		if allowed == to {
+			return true
+		}
+	}
+	return false
+}
+
+// Transition moves task to given status and records when it happened
+func (t *Task) Transition(to Status, at time.Time) error {
+	if t.IsNote {
+		return errors.WithMessagef(ErrIllegalTransition, "Task: %d is a note", t.Id)
+	}
+	from := statusOf(*t)
+	if !from.CanTransition(to) {
+		return errors.WithMessagef(ErrIllegalTransition, "Task: %d from %s to %s", t.Id, from, to)
+	}
+	if from == StatusInProgress {
+		t.PassedTime = t.Elapsed(at)
+		t.StartedAt = time.Time{}
+	}
+	if to == StatusInProgress {
+		t.StartedAt = at
+	}
+	t.Status = to
+	t.Transitions = append(t.Transitions, Transition{From: from, To: to, At: at})
+	t.record(transitionEvent(from, to), at, "")
+	return nil
+}
+
+// statusOf returns status of the task, tasks without status are pending
+func statusOf(t Task) Status {
+	if t.Status == "" {
+		return StatusPending
+	}
+	return t.Status
+}
+
+// legacyStatus maps boolean flags used by older storage files into status
+func legacyStatus(inProgress, cancelled, complete bool) Status {
+	switch {
+	case complete:
+		return StatusDone
+	case cancelled:
+		return StatusCancelled
+	case inProgress:
+		return StatusInProgress
+	default:
+		return StatusPending
+	}
+}
+
+// UnmarshalJSON reads status from files written before status was introduced
+func (t *Task) UnmarshalJSON(data []byte) error {
+	type plain Task
+	aux := struct {
+		*plain
+		InProgress bool `json:"inProgress"`
+		IsCanelled bool `json:"isCancelled"`
+		IsComplete bool `json:"isComplete"`
+	}{plain: (*plain)(t)}
+	if err := json.Unmarshal(data, &aux); err != nil {
+		return err
+	}
+	if t.Status == "" {
+		t.Status = legacyStatus(aux.InProgress, aux.IsCanelled, aux.IsComplete)
+	}
+	return nil
+}
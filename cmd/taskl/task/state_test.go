@@ -0,0 +1,88 @@
+package task
+
+import (
+	"encoding/json"
+	"errors"
+	"github.com/stretchr/testify/assert"
+	"testing"
+	"time"
+)
+
+func TestTask_TransitionRecordsHistory(t *testing.T) {
+	task := Task{Id: 1, Status: StatusPending}
+	started := time.Date(2021, 6, 8, 10, 0, 0, 0, time.UTC)
+	paused := started.Add(time.Hour)
+
+	assert.NoError(t, task.Transition(StatusInProgress, started))
+	assert.NoError(t, task.Transition(StatusPaused, paused))
+
+	assert.Equal(t, StatusPaused, task.Status)
+	assert.Equal(t, []Transition{
+		{From: StatusPending, To: StatusInProgress, At: started},
+		{From: StatusInProgress, To: StatusPaused, At: paused},
+	}, task.Transitions)
+}
+
+func TestTask_IllegalTransition(t *testing.T) {
+	task := Task{Id: 1, Status: StatusDone}
+
+	err := task.Transition(StatusInProgress, time.Now())
+	assert.True(t, errors.Is(err, ErrIllegalTransition))
+	assert.Equal(t, StatusDone, task.Status)
+	assert.Empty(t, task.Transitions)
+
+	assert.NoError(t, task.Transition(StatusPending, time.Now()))
+	assert.NoError(t, task.Transition(StatusInProgress, time.Now()))
+}
+
+func TestTask_UnmarshalLegacyFlags(t *testing.T) {
+	data := `{"tasks": [
+		{"id": 1, "description": "Started", "inProgress": true},
+		{"id": 2, "description": "Cancelled", "isCancelled": true},
+		{"id": 3, "description": "Completed", "isComplete": true, "inProgress": true},
+		{"id": 4, "description": "Pending"},
+		{"id": 5, "description": "New format", "status": "blocked"}
+	]}`
+	var tl TaskList
+	assert.NoError(t, json.Unmarshal([]byte(data), &tl))
+
+	var statuses []Status
+	for _, task := range tl.Tasks {
+		statuses = append(statuses, task.Status)
+	}
+	assert.Equal(t, []Status{StatusInProgress, StatusCancelled, StatusDone, StatusPending, StatusBlocked}, statuses)
+	assert.Equal(t, "Completed", tl.Tasks[2].Description)
+}
+
+func TestRepository_ReopenCompletedTask(t *testing.T) {
+	repository := newTestRepository()
+	created, err := repository.Create(Task{Description: "Reopen me"})
+	assert.NoError(t, err)
+
+	assert.NoError(t, repository.Complete(created.Id))
+	assert.True(t, errors.Is(repository.Complete(created.Id), ErrIllegalTransition))
+	assert.NoError(t, repository.Reopen(created.Id))
+
+	loaded, err := repository.Get(created.Id)
+	assert.NoError(t, err)
+	assert.Equal(t, StatusPending, loaded.Status)
+	assert.Equal(t, 2, len(loaded.Transitions))
+}
+
+func TestTask_ElapsedAccumulatesWorkingPeriods(t *testing.T) {
+	task := Task{Id: 1, Status: StatusPending}
+	start := time.Date(2021, 6, 8, 10, 0, 0, 0, time.UTC)
+
+	assert.NoError(t, task.Transition(StatusInProgress, start))
+	assert.Equal(t, 30*time.Minute, task.Elapsed(start.Add(30*time.Minute)))
+	assert.NoError(t, task.Transition(StatusPaused, start.Add(time.Hour)))
+	//paused time is not counted
+	assert.Equal(t, time.Hour, task.Elapsed(start.Add(5*time.Hour)))
+
+	assert.NoError(t, task.Transition(StatusInProgress, start.Add(6*time.Hour)))
+	assert.NoError(t, task.Transition(StatusDone, start.Add(6*time.Hour+15*time.Minute)))
+
+	assert.Equal(t, time.Hour+15*time.Minute, task.PassedTime)
+	assert.True(t, task.StartedAt.IsZero())
+	assert.Equal(t, task.PassedTime, task.Elapsed(start.Add(24*time.Hour)))
+}
@@ -0,0 +1,77 @@
+package task
+
+import (
+	"github.com/pkg/errors"
+)
+
+// ErrTaskNotFound is returned by stores when requested task id is unknown
+var ErrTaskNotFound = errors.New("task not found")
+
+// ErrTaskExists is returned by stores when created task id is already taken
+var ErrTaskExists = errors.New("task already exists")
+
+// ErrNotOnBoard is returned when operation is scoped to board task is not attached to
+var ErrNotOnBoard = errors.New("task does not belong to board")
+
+// Store is persistence backend used by Repository
+type Store interface {
+	Get(id int) (*Task, error)
+	List() (*TaskList, error)
+	Create(t Task) (*Task, error)
+	Update(t Task) error
+	Delete(id int) error
+	NextId() (int, error)
+	// Transaction runs fn with exclusive access to the store, changes made
+	// through tx are persisted only when fn returns nil
+	Transaction(fn func(tx Store) error) error
+}
+
+// indexedStore is implemented by stores able to look tasks up by board or
+// status without loading all of them
+type indexedStore interface {
+	ListByBoard(board string) (*TaskList, error)
+	ListByStatus(statuses ...Status) (*TaskList, error)
+}
+
+// lookup returns tasks which may match given board and statuses, callers
+// still filter the result as stores without indexes return all tasks
+func lookup(store Store, board string, statuses []Status) (*TaskList, error) {
+	if journaled, ok := store.(*journaledStore); ok {
+		store = journaled.Store
+	}
+	indexed, ok := store.(indexedStore)
+	switch {
+	case ok && len(statuses) > 0:
+		return indexed.ListByStatus(statuses...)
+	case ok && board != "":
+		return indexed.ListByBoard(board)
+	}
+	return store.List()
+}
+
+func nextId(tasks []Task) int {
+	var max int
+	for _, task := range tasks {
+		if max < task.Id {
+			max = task.Id
+		}
+	}
+	return max + 1
+}
+
+func indexOf(tasks []Task, id int) int {
+	for idx := range tasks {
+		if tasks[idx].Id == id {
+			return idx
+		}
+	}
+	return -1
+}
+
+func notFound(id int) error {
+	return errors.WithMessagef(ErrTaskNotFound, "Task with id: %d", id)
+}
+
+func alreadyExists(id int) error {
+	return errors.WithMessagef(ErrTaskExists, "Task with id: %d", id)
+}
@@ -0,0 +1,90 @@
+package task
+
+import (
+	"github.com/pkg/errors"
+	"time"
+)
+
+// SetAutoComplete enables completing parent task once all its subtasks are finished
+func (rep *Repository) SetAutoComplete(enabled bool) {
+	rep.autoComplete = enabled
+}
+
+// checkParent returns task new subtask is attached to
+func checkParent(tx Store, parentId int) (*Task, error) {
+	parent, err := tx.Get(parentId)
+	if err != nil {
+		return nil, errors.WithMessage(err, "Parent task")
+	}
+	if parent.IsNote {
+		return nil, errors.Errorf("Parent task: %d is a note", parentId)
+	}
+	return parent, nil
+}
+
+// Subtasks returns direct subtasks of given task, notes are skipped
+func (tl TaskList) Subtasks(parentId int) []Task {
+	var children []Task
+	for _, t := range tl.Tasks {
+		if t.ParentId == parentId && t.Id != parentId && !t.IsNote {
+			children = append(children, t)
+		}
+	}
+	return children
+}
+
+// completeParents completes parents of updated tasks once all their
+// subtasks are finished and at least one of them is done, completed
+// parents are checked up the hierarchy
+func completeParents(tx Store, updated []int, at time.Time) error {
+	tl, err := tx.List()
+	if err != nil {
+		return err
+	}
+	byId := map[int]int{}
+	for idx, t := range tl.Tasks {
+		byId[t.Id] = idx
+	}
+	var queue []int
+	for _, id := range updated {
+		if idx, ok := byId[id]; ok && tl.Tasks[idx].ParentId != 0 {
+			queue = append(queue, tl.Tasks[idx].ParentId)
+		}
+	}
+	visited := map[int]bool{}
+	for len(queue) > 0 {
+		parentId := queue[0]
+		queue = queue[1:]
+		idx, ok := byId[parentId]
+		if !ok || visited[parentId] {
+			continue
+		}
+		visited[parentId] = true
+		parent := &tl.Tasks[idx]
+		if !statusOf(*parent).CanTransition(StatusDone) || !subtasksDone(tl.Subtasks(parentId)) {
+			continue
+		}
+		if err := parent.Transition(StatusDone, at); err != nil {
+			return err
+		}
+		if err := tx.Update(*parent); err != nil {
+			return err
+		}
+		if parent.ParentId != 0 {
+			queue = append(queue, parent.ParentId)
+		}
+	}
+	return nil
+}
+
+// subtasksDone reports whether all subtasks are finished and at least one is done
+func subtasksDone(children []Task) bool {
+	var done bool
+	for _, child := range children {
+		if statusOf(child).IsOpen() {
+			return false
+		}
+		done = done || child.Status == StatusDone
+	}
+	return done
+}
@@ -0,0 +1,91 @@
+package task
+
+import (
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+func TestRepository_CreateSubtask(t *testing.T) {
+	repository := newTestRepository(
+		Task{Id: 1, Status: StatusPending, Boards: []string{"Work"}},
+		Task{Id: 2, Status: StatusPending, ParentId: 1, Boards: []string{"Work"}},
+		Task{Id: 3, Status: StatusPending, ParentId: 1, Boards: []string{"Work"}},
+		Task{Id: 4, Status: StatusPending, ParentId: 1, Boards: []string{"Work"}, IsNote: true},
+		Task{Id: 5, Status: StatusPending, IsNote: true},
+	)
+
+	created, err := repository.Create(Task{Description: "child", ParentId: 1})
+	assert.NoError(t, err)
+	assert.Equal(t, 1, created.ParentId)
+	assert.Equal(t, []string{"Work"}, created.Boards)
+
+	created, err = repository.Create(Task{Description: "child", ParentId: 1, Boards: []string{"Home"}})
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"Home"}, created.Boards)
+
+	_, err = repository.Create(Task{Description: "orphan", ParentId: 42})
+	assert.ErrorIs(t, err, ErrTaskNotFound)
+
+	_, err = repository.Create(Task{Description: "under note", ParentId: 5})
+	assert.Error(t, err)
+}
+
+func TestRepository_TransitionAllCompletesParent(t *testing.T) {
+	repository := newTestRepository(
+		Task{Id: 1, Status: StatusPending, Boards: []string{"Work"}},
+		Task{Id: 2, Status: StatusPending, ParentId: 1, Boards: []string{"Work"}},
+		Task{Id: 3, Status: StatusPending, ParentId: 1, Boards: []string{"Work"}},
+		Task{Id: 4, Status: StatusPending, ParentId: 1, Boards: []string{"Work"}, IsNote: true},
+		Task{Id: 5, Status: StatusPending, IsNote: true},
+	)
+	repository.SetAutoComplete(true)
+
+	_, err := repository.TransitionAll([]int{2}, StatusDone, "")
+	assert.NoError(t, err)
+	assert.Equal(t, StatusPending, storedTasks(t, repository)[1].Status)
+
+	_, err = repository.TransitionAll([]int{3}, StatusCancelled, "")
+	assert.NoError(t, err)
+	assert.Equal(t, StatusDone, storedTasks(t, repository)[1].Status)
+}
+
+func TestRepository_TransitionAllKeepsParent(t *testing.T) {
+	repository := newTestRepository(
+		Task{Id: 1, Status: StatusPending, Boards: []string{"Work"}},
+		Task{Id: 2, Status: StatusPending, ParentId: 1, Boards: []string{"Work"}},
+		Task{Id: 3, Status: StatusPending, ParentId: 1, Boards: []string{"Work"}},
+		Task{Id: 4, Status: StatusPending, ParentId: 1, Boards: []string{"Work"}, IsNote: true},
+		Task{Id: 5, Status: StatusPending, IsNote: true},
+	)
+
+	_, err := repository.TransitionAll([]int{2, 3}, StatusDone, "")
+	assert.NoError(t, err)
+	assert.Equal(t, StatusPending, storedTasks(t, repository)[1].Status)
+
+	repository = newTestRepository(
+		Task{Id: 1, Status: StatusPending, Boards: []string{"Work"}},
+		Task{Id: 2, Status: StatusPending, ParentId: 1, Boards: []string{"Work"}},
+		Task{Id: 3, Status: StatusPending, ParentId: 1, Boards: []string{"Work"}},
+		Task{Id: 4, Status: StatusPending, ParentId: 1, Boards: []string{"Work"}, IsNote: true},
+		Task{Id: 5, Status: StatusPending, IsNote: true},
+	)
+	repository.SetAutoComplete(true)
+	_, err = repository.TransitionAll([]int{2, 3}, StatusCancelled, "")
+	assert.NoError(t, err)
+	assert.Equal(t, StatusPending, storedTasks(t, repository)[1].Status)
+}
+
+func TestRepository_TransitionAllCompletesAncestors(t *testing.T) {
+	repository := newTestRepository(
+		Task{Id: 1, Status: StatusPending},
+		Task{Id: 2, Status: StatusPending, ParentId: 1},
+		Task{Id: 3, Status: StatusPending, ParentId: 2},
+	)
+	repository.SetAutoComplete(true)
+
+	_, err := repository.TransitionAll([]int{3}, StatusDone, "")
+	assert.NoError(t, err)
+	tasks := storedTasks(t, repository)
+	assert.Equal(t, StatusDone, tasks[2].Status)
+	assert.Equal(t, StatusDone, tasks[1].Status)
+}
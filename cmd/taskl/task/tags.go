@@ -0,0 +1,93 @@
+package task
+
+import (
+	"regexp"
+	"strings"
+)
+
+const (
+	tagMarker     = "+"
+	contextMarker = "@"
+)
+
+var tagPattern = regexp.MustCompile(`^[+@][\p{L}_][\p{L}\p{N}_\-/.]*$`)
+
+// IsTagToken reports whether word is +tag or @context token
+func IsTagToken(word string) bool {
+	return tagPattern.MatchString(word)
+}
+
+// ExtractTags removes +tag and @context tokens from description, returned
+// tags and contexts are without markers and duplicates
+func ExtractTags(description string) (string, []string, []string) {
+	var words, tags, contexts []string
+	for _, word := range strings.Fields(description) {
+		switch {
+		case !IsTagToken(word):
+			words = append(words, word)
+		case strings.HasPrefix(word, tagMarker):
+			tags = addLabel(tags, strings.TrimPrefix(word, tagMarker))
+		default:
+			contexts = addLabel(contexts, strings.TrimPrefix(word, contextMarker))
+		}
+	}
+	return strings.Join(words, " "), tags, contexts
+}
+
+// addLabel appends label unless already present, case is ignored
+func addLabel(labels []string, label string) []string {
+	if hasLabel(labels, label) {
+		return labels
+	}
+	return append(labels, label)
+}
+
+func hasLabel(labels []string, label string) bool {
+	for _, l := range labels {
+		if strings.EqualFold(l, label) {
+			return true
+		}
+	}
+	return false
+}
+
+// HasTag reports whether task is tagged with given tag, case is ignored
+func (t Task) HasTag(tag string) bool {
+	return hasLabel(t.Tags, tag)
+}
+
+// HasContext reports whether task has given context, case is ignored
+func (t Task) HasContext(context string) bool {
+	return hasLabel(t.Contexts, context)
+}
+
+// Labels returns tags and contexts with their markers
+func (t Task) Labels() []string {
+	var labels []string
+	for _, tag := range t.Tags {
+		labels = append(labels, tagMarker+tag)
+	}
+	for _, context := range t.Contexts {
+		labels = append(labels, contextMarker+context)
+	}
+	return labels
+}
+
+// MatchLabels reports whether task has all +tag and @context labels
+func (t Task) MatchLabels(labels []string) bool {
+	for _, label := range labels {
+		switch {
+		case strings.HasPrefix(label, tagMarker):
+			if !t.HasTag(strings.TrimPrefix(label, tagMarker)) {
+				return false
+			}
+		case strings.HasPrefix(label, contextMarker):
+			if !t.HasContext(strings.TrimPrefix(label, contextMarker)) {
+				return false
+			}
+		default:
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,67 @@
+package task
+
+import (
+	"github.com/stretchr/testify/assert"
+	"testing"
+	"time"
+)
+
+func TestExtractTags(t *testing.T) {
+	assert := assert.New(t)
+	description, tags, contexts := ExtractTags("Fix +backend login @office bug +Backend +api for ann@example.com in C++")
+	assert.Equal("Fix login bug for ann@example.com in C++", description)
+	assert.Equal([]string{"backend", "api"}, tags)
+	assert.Equal([]string{"office"}, contexts)
+
+	description, tags, contexts = ExtractTags("Give +1 to @ 5")
+	assert.Equal("Give +1 to @ 5", description)
+	assert.Empty(tags)
+	assert.Empty(contexts)
+}
+
+func TestTask_MatchLabels(t *testing.T) {
+	assert := assert.New(t)
+	task := Task{Tags: []string{"backend", "api"}, Contexts: []string{"office"}}
+	assert.Equal([]string{"+backend", "+api", "@office"}, task.Labels())
+	assert.True(task.MatchLabels([]string{"+Backend", "@office"}))
+	assert.True(task.MatchLabels(nil))
+	assert.False(task.MatchLabels([]string{"+backend", "@home"}))
+	assert.False(task.MatchLabels([]string{"backend"}))
+}
+
+func TestLabelsInQueryAndSearch(t *testing.T) {
+	assert := assert.New(t)
+	tasks := TaskList{Tasks: []Task{
+		{Id: 1, Description: "Login", Tags: []string{"backend"}, Contexts: []string{"office"}},
+		{Id: 2, Description: "Styles", Tags: []string{"frontend"}},
+	}}
+	for input, expected := range map[string][]int{
+		`+backend`:        {1},
+		`-+backend`:       {2},
+		`tag:frontend`:    {2},
+		`context!=office`: {2},
+	} {
+		q, err := ParseQuery(input, time.Now())
+		assert.NoError(err, input)
+		var ids []int
+		for _, t := range q.Apply(tasks).Tasks {
+			ids = append(ids, t.Id)
+		}
+		assert.Equal(expected, ids, input)
+	}
+
+	matcher, err := NewMatcher([]string{"front"}, false)
+	assert.NoError(err)
+	assert.Equal(1, len(tasks.Filter(matcher.Match).Tasks))
+}
+
+func TestRepository_EditAddsTags(t *testing.T) {
+	assert := assert.New(t)
+	repository := newTestRepository(Task{Id: 1, Description: "Login", Tags: []string{"backend"}})
+
+	edited, err := repository.Edit(1, Edit{AddTags: []string{"Backend", "urgent"}, AddContexts: []string{"office"}})
+	assert.NoError(err)
+	assert.Equal([]string{"backend", "urgent"}, edited.Tags)
+	assert.Equal([]string{"office"}, edited.Contexts)
+	assert.Equal("Login", edited.Description)
+}
@@ -1,17 +1,15 @@
 package task
 
 import (
-	"encoding/json"
 	"fmt"
 	"github.com/pkg/errors"
-	"io/ioutil"
 	"log"
-	"os"
-	"path/filepath"
+	"sort"
 	"time"
 )
 
-const storageFilename = "taskl.json"
+// DefaultBoard is used when task is not attached to any board
+const DefaultBoard = "My Board"
 
 var verbose = false
 
@@ -26,152 +24,460 @@ type Task struct {
 	Date        time.Time `json:"date"`
 	Description string    `json:"description"`
 	Boards      []string  `json:"boards"`
-	InProgress  bool      `json:"inProgress"`
-	IsCanelled  bool      `json:"isCancelled"`
-	IsComplete  bool      `json:"isComplete"`
+	Status      Status    `json:"status"`
+	// Priority is one of PriorityNormal, PriorityMedium or PriorityHigh
+	Priority  int  `json:"priority,omitempty"`
+	IsStarred bool `json:"isStarred,omitempty"`
+	// IsNote marks non actionable items, notes have no status changes
+	IsNote bool `json:"isNote,omitempty"`
+	// ParentId is id of the task this one is subtask of, 0 for top level tasks
+	ParentId int `json:"parentId,omitempty"`
+	// DependsOn lists ids of tasks which have to be finished before this one starts
+	DependsOn []int `json:"dependsOn,omitempty"`
+	// Tags and Contexts are parsed from +tag and @context description tokens
+	Tags     []string `json:"tags,omitempty"`
+	Contexts []string `json:"contexts,omitempty"`
+	// DueDate is midnight of the day task is due, zero when not set
+	DueDate time.Time `json:"dueDate"`
+	// Recurrence, when set, creates next occurrence once the task is completed
+	Recurrence *Recurrence `json:"recurrence,omitempty"`
+	// Transitions keeps every status change in order it happened
+	Transitions []Transition `json:"transitions,omitempty"`
+	// StartedAt is set while task is in progress, PassedTime accumulates
+	// time of all finished working periods
+	StartedAt  time.Time     `json:"startedAt"`
+	PassedTime time.Duration `json:"passedTime,omitempty"`
+	// Estimate is expected working time, zero when not estimated
+	Estimate time.Duration `json:"estimate,omitempty"`
+	// History is append only audit log of the task
+	History []Event `json:"history,omitempty"`
+}
+
+// Elapsed returns total working time including currently running period
+func (t Task) Elapsed(now time.Time) time.Duration {
+	elapsed := t.PassedTime
+	if t.Status == StatusInProgress && !t.StartedAt.IsZero() && now.After(t.StartedAt) {
+		elapsed += now.Sub(t.StartedAt)
+	}
+	return elapsed
+}
+
+// clone returns copy of the task which does not share slices with original
+func (t Task) clone() Task {
+	if t.Boards != nil {
+		t.Boards = append([]string(nil), t.Boards...)
+	}
+	if t.Recurrence != nil {
+		recurrence := *t.Recurrence
+		recurrence.Days = append([]time.Weekday(nil), recurrence.Days...)
+		t.Recurrence = &recurrence
+	}
+	if t.DependsOn != nil {
+		t.DependsOn = append([]int(nil), t.DependsOn...)
+	}
+	if t.Tags != nil {
+		t.Tags = append([]string(nil), t.Tags...)
+	}
+	if t.Contexts != nil {
+		t.Contexts = append([]string(nil), t.Contexts...)
+	}
+	if t.Transitions != nil {
+		t.Transitions = append([]Transition(nil), t.Transitions...)
+	}
+	if t.History != nil {
+		t.History = append([]Event(nil), t.History...)
+	}
+	return t
+}
+
+// OnBoard reports whether task is attached to given board, tasks without
+// boards belong to DefaultBoard
+func (t Task) OnBoard(board string) bool {
+	if len(t.Boards) == 0 {
+		return board == DefaultBoard
+	}
+	for _, b := range t.Boards {
+		if b == board {
+			return true
+		}
+	}
+	return false
 }
 
 type TaskList struct {
 	Tasks []Task `json:"tasks"`
 }
 
+// Filter returns tasks matching predicate
+func (tl TaskList) Filter(keep func(t Task) bool) TaskList {
+	var filtered TaskList
+	for _, t := range tl.Tasks {
+		if keep(t) {
+			filtered.Tasks = append(filtered.Tasks, t)
+		}
+	}
+	return filtered
+}
+
+// Repository implements task operations on top of configured Store
 type Repository struct {
-	StoragePath string
+	store Store
+	// archive keeps removed tasks, archive operations fail when it is nil
+	archive Store
+	// autoComplete completes parent task once all its subtasks are finished
+	autoComplete bool
+	// journal records operations which can be undone, nil disables undo
+	journal Journal
 }
 
+// NewRepository creates repository backed by json file located in storagePath
 func NewRepository(storagePath string) *Repository {
-	dbPath := filepath.Join(storagePath, storageFilename)
-	err := os.MkdirAll(storagePath, os.ModePerm)
+	store, err := NewFileStore(storagePath)
 	if err != nil {
 		log.Fatal(err)
 	}
-	return &Repository{StoragePath: dbPath}
+	return NewRepositoryWithStore(store)
+}
+
+func NewRepositoryWithStore(store Store) *Repository {
+	return &Repository{store: store}
 }
 
-type action func(task *Task)
+// NewRepositoryWithArchive creates repository moving removed tasks into archive store
+func NewRepositoryWithArchive(store, archive Store) *Repository {
+	return &Repository{store: store, archive: archive}
+}
+
+type action func(task *Task) error
 
 func (to *Repository) update(id int, updateStrategy action) error {
-	tl, err := to.GetAll()
-	if err != nil {
-		return err
-	}
-	taskIdx := -1
-	for idx := range tl.Tasks {
-		if tl.Tasks[idx].Id == id {
-			taskIdx = idx
-			break
+	return to.store.Transaction(func(tx Store) error {
+		task, err := tx.Get(id)
+		if err != nil {
+			return err
 		}
-	}
-	if taskIdx < 0 {
-		return fmt.Errorf("Update: Task with id: %d does not exists", id)
-	}
-	updateStrategy(&tl.Tasks[taskIdx])
-	return to.save(tl)
+		if err := updateStrategy(task); err != nil {
+			return err
+		}
+		return tx.Update(*task)
+	})
 }
 
 func (rep *Repository) Delete(id int) error {
-	tl, err := rep.GetAll()
+	return rep.store.Delete(id)
+}
+
+// Result holds outcome of batch operation for single task
+type Result struct {
+	Id  int
+	Err error
+}
+
+// updateAll applies updateStrategy to every task in single transaction.
+// Failure of one task does not stop others, it is reported in its Result.
+func (to *Repository) updateAll(ids []int, updateStrategy action) ([]Result, error) {
+	return to.updateAllThen(ids, func(_ Store, task *Task) error {
+		return updateStrategy(task)
+	}, nil)
+}
+
+// updateAllThen works like updateAll, updateStrategy can read other tasks
+// through the transaction, finish runs afterwards in the same transaction
+// with ids of successfully updated tasks
+func (to *Repository) updateAllThen(ids []int, updateStrategy func(tx Store, task *Task) error, finish func(tx Store, updated []int) error) ([]Result, error) {
+	var results []Result
+	err := to.store.Transaction(func(tx Store) error {
+		results = make([]Result, 0, len(ids))
+		for _, id := range ids {
+			result := Result{Id: id}
+			task, err := tx.Get(id)
+			if err == nil {
+				err = updateStrategy(tx, task)
+			}
+			if err == nil {
+				err = tx.Update(*task)
+			}
+			if err != nil && !isTaskError(err) {
+				return err
+			}
+			result.Err = err
+			results = append(results, result)
+		}
+		if finish == nil {
+			return nil
+		}
+		var updated []int
+		for _, r := range results {
+			if r.Err == nil {
+				updated = append(updated, r.Id)
+			}
+		}
+		return finish(tx, updated)
+	})
 	if err != nil {
-		return err
+		return nil, err
 	}
-	var updated []Task
-	for _, task := range tl.Tasks {
-		if task.Id != id {
-			updated = append(updated, task)
+	return results, nil
+}
+
+// isTaskError reports whether err concerns single task and should not abort whole batch
+func isTaskError(err error) bool {
+	return errors.Is(err, ErrTaskNotFound) || errors.Is(err, ErrIllegalTransition) || errors.Is(err, ErrNotOnBoard) ||
+		errors.Is(err, ErrTaskExists) || errors.Is(err, ErrUnfinishedDependencies)
+}
+
+// checkBoard returns ErrNotOnBoard when board is set and task is not attached to it
+func checkBoard(task Task, board string) error {
+	if board != "" && !task.OnBoard(board) {
+		return errors.WithMessagef(ErrNotOnBoard, "Task: %d, board: %s", task.Id, board)
+	}
+	return nil
+}
+
+// TransitionAll moves all tasks to given status in single transaction,
+// when board is not empty only tasks attached to it are updated
+func (rep *Repository) TransitionAll(ids []int, to Status, board string) ([]Result, error) {
+	return rep.transitionAll(ids, to, board, nil)
+}
+
+// transitionAll works like TransitionAll, check runs before every transition
+func (rep *Repository) transitionAll(ids []int, to Status, board string, check func(tx Store, task Task) error) ([]Result, error) {
+	now := time.Now()
+	finish := func(tx Store, updated []int) error {
+		if to == StatusDone {
+			if err := rep.spawnOccurrences(tx, updated, now); err != nil {
+				return err
+			}
+		}
+		if rep.autoComplete && !to.IsOpen() {
+			return completeParents(tx, updated, now)
 		}
+		return nil
 	}
-	tl.Tasks = updated
-	return rep.save(tl)
+	return rep.updateAllThen(ids, func(tx Store, task *Task) error {
+		if err := checkBoard(*task, board); err != nil {
+			return err
+		}
+		if check != nil {
+			if err := check(tx, *task); err != nil {
+				return err
+			}
+		}
+		return task.Transition(to, now)
+	}, finish)
 }
 
-func (rep *Repository) Start(id int) error {
-	return rep.update(id, func(task *Task) {
-		Log("Updating task: %+v", task)
-		task.InProgress = true
-		task.IsCanelled = false
-		task.IsComplete = false
+// DeleteAll removes all tasks in single transaction, when board is not
+// empty only tasks attached to it are removed
+func (rep *Repository) DeleteAll(ids []int, board string) ([]Result, error) {
+	var results []Result
+	err := rep.store.Transaction(func(tx Store) error {
+		results = make([]Result, 0, len(ids))
+		for _, id := range ids {
+			task, err := tx.Get(id)
+			if err == nil {
+				err = checkBoard(*task, board)
+			}
+			if err == nil {
+				err = tx.Delete(id)
+			}
+			if err != nil && !isTaskError(err) {
+				return err
+			}
+			results = append(results, Result{Id: id, Err: err})
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return results, nil
+}
+
+// transition moves task to given status
+func (rep *Repository) transition(id int, to Status) error {
+	return rep.update(id, func(task *Task) error {
+		Log("Updating task: %+v, status: %s", task, to)
+		return task.Transition(to, time.Now())
 	})
 }
 
+func (rep *Repository) Start(id int) error {
+	return rep.transition(id, StatusInProgress)
+}
+
+func (rep *Repository) Pause(id int) error {
+	return rep.transition(id, StatusPaused)
+}
+
+func (rep *Repository) Block(id int) error {
+	return rep.transition(id, StatusBlocked)
+}
+
+// Reopen moves finished or blocked task back to pending
+func (rep *Repository) Reopen(id int) error {
+	return rep.transition(id, StatusPending)
+}
+
 func (rep *Repository) Cancel(id int) error {
-	return rep.update(id, func(task *Task) {
-		task.IsCanelled = true
-		task.IsComplete = false
-		task.InProgress = false
-	})
+	return rep.transition(id, StatusCancelled)
 }
 
+// Complete finishes the task, recurring task gets its next occurrence
 func (rep *Repository) Complete(id int) error {
-	return rep.update(id, func(task *Task) {
-		task.IsComplete = true
-		task.InProgress = false
-		task.IsCanelled = false
-	})
+	results, err := rep.TransitionAll([]int{id}, StatusDone, "")
+	if err != nil {
+		return err
+	}
+	return results[0].Err
+}
+
+func (to *Repository) Get(id int) (*Task, error) {
+	return to.store.Get(id)
 }
 
 func (to *Repository) GetAll() (*TaskList, error) {
-	if _, err := os.Stat(to.StoragePath); os.IsNotExist(err) {
-		//Log("Database file not exists, loc: %v", to.StoragePath)
-		return &TaskList{}, nil
-	}
+	return to.store.List()
+}
+
+// SetStarred stars or unstars all tasks in single transaction
+func (rep *Repository) SetStarred(ids []int, starred bool) ([]Result, error) {
+	now := time.Now()
+	return rep.updateAll(ids, func(task *Task) error {
+		if task.IsStarred == starred {
+			return nil
+		}
+		task.IsStarred = starred
+		if starred {
+			task.record(EventStarred, now, "")
+		} else {
+			task.record(EventUnstarred, now, "")
+		}
+		return nil
+	})
+}
+
+// SetNote converts tasks into notes or notes back into tasks in single
+// transaction, tasks converted into notes are moved back to pending
+func (rep *Repository) SetNote(ids []int, note bool) ([]Result, error) {
+	now := time.Now()
+	return rep.updateAll(ids, func(task *Task) error {
+		if note && statusOf(*task) != StatusPending {
+			if err := task.Transition(StatusPending, now); err != nil {
+				return err
+			}
+		}
+		if task.IsNote == note {
+			return nil
+		}
+		task.IsNote = note
+		if note {
+			task.record(EventConverted, now, "to note")
+		} else {
+			task.record(EventConverted, now, "to task")
+		}
+		return nil
+	})
+}
 
-	var tasks TaskList
-	data, err := ioutil.ReadFile(to.StoragePath)
+// BoardTasks returns tasks attached to board ordered by id, position in
+// returned slice + 1 is board local task number
+func (to *Repository) BoardTasks(board string) ([]Task, error) {
+	tl, err := lookup(to.store, board, nil)
 	if err != nil {
-		return nil, errors.WithMessagef(err, "Failed to read storage file, loc: %v", to.StoragePath)
+		return nil, err
 	}
-	err = json.Unmarshal(data, &tasks)
-	if err != nil {
-		return nil, errors.WithMessage(err, "Failed to unmarshal storage")
+	var tasks []Task
+	for _, t := range tl.Tasks {
+		if t.OnBoard(board) {
+			tasks = append(tasks, t)
+		}
 	}
+	sort.Slice(tasks, func(i, j int) bool { return tasks[i].Id < tasks[j].Id })
+	return tasks, nil
+}
 
-	return &tasks, nil
+// ResolveLocalId returns global id of the task with given board local number
+func (to *Repository) ResolveLocalId(board string, number int) (int, error) {
+	tasks, err := to.BoardTasks(board)
+	if err != nil {
+		return -1, err
+	}
+	if number < 1 || number > len(tasks) {
+		return -1, fmt.Errorf("Board: %s has no task with number: %d", board, number)
+	}
+	return tasks[number-1].Id, nil
 }
 
 func (to *Repository) Create(t Task) (*Task, error) {
 	Log("Creating task: %+v", t)
-	if t.Id < 1 {
-		id, err := to.nextId()
-		if err != nil {
-			return nil, err
-		}
-		t.Id = id
-	}
 	t.Date = time.Now()
-	allTasks, err := to.GetAll()
-	if err != nil {
-		return nil, err
+	if t.Status == "" {
+		t.Status = StatusPending
 	}
-	allTasks.Tasks = append(allTasks.Tasks, t)
-	data, err := json.MarshalIndent(allTasks, "", " ")
+	t.record(EventCreated, t.Date, "")
+	var created *Task
+	err := to.store.Transaction(func(tx Store) error {
+		if t.ParentId != 0 {
+			parent, err := checkParent(tx, t.ParentId)
+			if err != nil {
+				return err
+			}
+			if len(t.Boards) == 0 {
+				t.Boards = append([]string(nil), boardsOf(*parent)...)
+			}
+		}
+		if t.Id < 1 {
+			if err := to.assignId(tx, &t); err != nil {
+				return err
+			}
+		}
+		var err error
+		created, err = tx.Create(t)
+		return err
+	})
 	if err != nil {
-		return nil, errors.WithMessage(err, "Repository: Failed to marshal tasks")
-	}
-	Log("Create: storing data, loc: %v, data: %v", to.StoragePath, allTasks)
-	if err := ioutil.WriteFile(to.StoragePath, data, 0644); err != nil {
 		return nil, err
 	}
-	return &t, nil
+	return created, nil
 }
-func (to *Repository) save(list *TaskList) error {
-	data, err := json.MarshalIndent(list, "", " ")
-	if err != nil {
-		return errors.WithMessage(err, "Repository: Failed to marshal tasks")
+
+// idTaken reports whether id can not be given to new task, ids of archived
+// tasks are kept for restore
+func (to *Repository) idTaken(tx Store, id int) (bool, error) {
+	if id < 1 {
+		return true, nil
+	}
+	stores := []Store{tx}
+	if to.archive != nil {
+		stores = append(stores, to.archive)
 	}
-	Log("save: storing  data: %v", list)
-	return ioutil.WriteFile(to.StoragePath, data, 0644)
+	for _, store := range stores {
+		_, err := store.Get(id)
+		if err == nil {
+			return true, nil
+		}
+		if !errors.Is(err, ErrTaskNotFound) {
+			return false, err
+		}
+	}
+	return false, nil
 }
 
-func (rep *Repository) nextId() (int, error) {
-	tl, err := rep.GetAll()
+// assignId picks id of new task, without archive store picks it on create
+func (to *Repository) assignId(tx Store, t *Task) error {
+	if to.archive == nil {
+		return nil
+	}
+	// ids of archived tasks are not reused, so they can be restored under original id
+	id, err := tx.NextId()
 	if err != nil {
-		return -1, err
+		return err
 	}
-	var max int
-	for _, task := range tl.Tasks {
-		if max < task.Id {
-			max = task.Id
-		}
+	archived, err := to.archive.NextId()
+	if err != nil {
+		return err
 	}
-	return max + 1, nil
+	t.Id = max(id, archived)
+	return nil
 }
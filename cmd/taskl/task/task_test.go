@@ -1,11 +1,13 @@
 package task
 
 import (
+	"errors"
 	"github.com/google/go-cmp/cmp"
 	"github.com/stretchr/testify/assert"
 	"log"
 	"os"
 	"strconv"
+	"sync"
 	"testing"
 )
 
@@ -160,14 +162,14 @@ func TestRepository_Start(t *testing.T) {
 	loaded, err := repository.GetAll()
 	assert.NoError(t, err)
 	assert.Equal(t, 1, len(loaded.Tasks))
-	assert.Equal(t, loaded.Tasks[0].InProgress, false)
+	assert.Equal(t, loaded.Tasks[0].Status, StatusPending)
 
 	err = repository.Start(task.Id)
 	assert.NoError(t, err)
 
 	loaded, err = repository.GetAll()
 	assert.NoError(t, err)
-	assert.Equal(t, true, loaded.Tasks[0].InProgress)
+	assert.Equal(t, StatusInProgress, loaded.Tasks[0].Status)
 
 }
 
@@ -187,14 +189,14 @@ func TestRepository_Cancel(t *testing.T) {
 	loaded, err := repository.GetAll()
 	assert.NoError(t, err)
 	assert.Equal(t, 1, len(loaded.Tasks))
-	assert.Equal(t, loaded.Tasks[0].IsCanelled, false)
+	assert.Equal(t, loaded.Tasks[0].Status, StatusPending)
 
 	err = repository.Cancel(task.Id)
 	assert.NoError(t, err)
 
 	loaded, err = repository.GetAll()
 	assert.NoError(t, err)
-	assert.Equal(t, true, loaded.Tasks[0].IsCanelled)
+	assert.Equal(t, StatusCancelled, loaded.Tasks[0].Status)
 
 }
 func TestRepository_Done(t *testing.T) {
@@ -213,14 +215,14 @@ func TestRepository_Done(t *testing.T) {
 	loaded, err := repository.GetAll()
 	assert.NoError(t, err)
 	assert.Equal(t, 1, len(loaded.Tasks))
-	assert.Equal(t, loaded.Tasks[0].IsComplete, false)
+	assert.Equal(t, loaded.Tasks[0].Status, StatusPending)
 
 	err = repository.Complete(task.Id)
 	assert.NoError(t, err)
 
 	loaded, err = repository.GetAll()
 	assert.NoError(t, err)
-	assert.Equal(t, true, loaded.Tasks[0].IsComplete)
+	assert.Equal(t, StatusDone, loaded.Tasks[0].Status)
 
 }
 
@@ -232,3 +234,156 @@ func TestConvertion(t *testing.T) {
 	log.Println(val)
 	log.Println("---")
 }
+
+func TestMemoryStore_CreateUpdateDelete(t *testing.T) {
+	repository := NewRepositoryWithStore(NewMemoryStore())
+
+	created, err := repository.Create(Task{Description: "In memory", Boards: []string{"MyBoard"}})
+	assert.NoError(t, err)
+	assert.Equal(t, 1, created.Id)
+
+	err = repository.Start(created.Id)
+	assert.NoError(t, err)
+
+	loaded, err := repository.Get(created.Id)
+	assert.NoError(t, err)
+	assert.Equal(t, StatusInProgress, loaded.Status)
+
+	//returned tasks must not alias stored ones
+	loaded.Boards[0] = "Changed"
+	loaded, err = repository.Get(created.Id)
+	assert.NoError(t, err)
+	assert.Equal(t, "MyBoard", loaded.Boards[0])
+
+	err = repository.Delete(created.Id)
+	assert.NoError(t, err)
+	all, err := repository.GetAll()
+	assert.NoError(t, err)
+	assert.Equal(t, 0, len(all.Tasks))
+}
+
+func TestRepository_UnknownTask(t *testing.T) {
+	repository := NewRepositoryWithStore(NewMemoryStore())
+
+	err := repository.Complete(42)
+	assert.True(t, errors.Is(err, ErrTaskNotFound))
+
+	err = repository.Delete(42)
+	assert.True(t, errors.Is(err, ErrTaskNotFound))
+}
+
+func TestFileStore_ConcurrentCreateKeepsAllTasks(t *testing.T) {
+	f, err := os.MkdirTemp("", "")
+	assert.NoError(t, err)
+	defer os.RemoveAll(f)
+
+	const writers = 20
+	var wg sync.WaitGroup
+	for i := 0; i < writers; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			//every writer opens its own store, as separate processes would
+			repository := NewRepository(f)
+			_, err := repository.Create(Task{Description: "Task " + strconv.Itoa(i)})
+			assert.NoError(t, err)
+		}(i)
+	}
+	wg.Wait()
+
+	loaded, err := NewRepository(f).GetAll()
+	assert.NoError(t, err)
+	assert.Equal(t, writers, len(loaded.Tasks))
+	ids := map[int]bool{}
+	for _, task := range loaded.Tasks {
+		ids[task.Id] = true
+	}
+	assert.Equal(t, writers, len(ids))
+}
+
+func TestFileStore_FailedTransactionKeepsFile(t *testing.T) {
+	f, err := os.MkdirTemp("", "")
+	assert.NoError(t, err)
+	defer os.RemoveAll(f)
+
+	store, err := NewFileStore(f)
+	assert.NoError(t, err)
+	_, err = store.Create(Task{Description: "Keep me"})
+	assert.NoError(t, err)
+
+	err = store.Transaction(func(tx Store) error {
+		assert.NoError(t, tx.Delete(1))
+		return errors.New("abort")
+	})
+	assert.Error(t, err)
+
+	loaded, err := store.List()
+	assert.NoError(t, err)
+	assert.Equal(t, 1, len(loaded.Tasks))
+
+	//no temporary files are left behind
+	entries, err := os.ReadDir(f)
+	assert.NoError(t, err)
+	for _, e := range entries {
+		assert.NotContains(t, e.Name(), ".tmp")
+	}
+}
+
+func TestRepository_TransitionAllInSingleWrite(t *testing.T) {
+	f, err := os.MkdirTemp("", "")
+	assert.NoError(t, err)
+	defer os.RemoveAll(f)
+
+	repository := NewRepository(f)
+	for i := 0; i < 3; i++ {
+		_, err := repository.Create(Task{Description: "Batch " + strconv.Itoa(i), Boards: []string{"Work"}})
+		assert.NoError(t, err)
+	}
+	_, err = repository.Create(Task{Description: "Other board", Boards: []string{"Home"}})
+	assert.NoError(t, err)
+
+	results, err := repository.TransitionAll([]int{1, 2, 4, 7}, StatusDone, "Work")
+	assert.NoError(t, err)
+	assert.Equal(t, 4, len(results))
+	assert.NoError(t, results[0].Err)
+	assert.NoError(t, results[1].Err)
+	assert.True(t, errors.Is(results[2].Err, ErrNotOnBoard))
+	assert.True(t, errors.Is(results[3].Err, ErrTaskNotFound))
+
+	loaded, err := repository.GetAll()
+	assert.NoError(t, err)
+	var statuses []Status
+	for _, task := range loaded.Tasks {
+		statuses = append(statuses, task.Status)
+	}
+	assert.Equal(t, []Status{StatusDone, StatusDone, StatusPending, StatusPending}, statuses)
+
+	results, err = repository.DeleteAll([]int{3, 4}, "Work")
+	assert.NoError(t, err)
+	assert.NoError(t, results[0].Err)
+	assert.True(t, errors.Is(results[1].Err, ErrNotOnBoard))
+}
+
+// newTestRepository creates repository keeping given tasks in memory
+func newTestRepository(tasks ...Task) *Repository {
+	return NewRepositoryWithStore(NewMemoryStore(tasks...))
+}
+
+// storedTasks returns tasks of repository by id
+func storedTasks(t *testing.T, repository *Repository) map[int]Task {
+	tl, err := repository.GetAll()
+	assert.NoError(t, err)
+	tasks := map[int]Task{}
+	for _, task := range tl.Tasks {
+		tasks[task.Id] = task
+	}
+	return tasks
+}
+
+func taskIds(tasks []Task) []int {
+	var ids []int
+	for _, task := range tasks {
+		ids = append(ids, task.Id)
+	}
+	return ids
+}
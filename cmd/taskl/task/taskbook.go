@@ -0,0 +1,135 @@
+package task
+
+import (
+	"encoding/json"
+	"github.com/pkg/errors"
+	"sort"
+	"strings"
+	"time"
+)
+
+const taskbookDateLayout = "Mon Jan 02 2006"
+
+// TaskbookItem is single entry of taskbook/taskline storage file
+type TaskbookItem struct {
+	Id            int      `json:"id"`
+	Date          string   `json:"date"`
+	Timestamp     int64    `json:"timestamp"`
+	IsTask        bool     `json:"isTask"`
+	Description   string   `json:"description"`
+	IsStarred     bool     `json:"isStarred"`
+	Boards        []string `json:"boards"`
+	Priority      int      `json:"priority"`
+	InProgress    bool     `json:"inProgress"`
+	IsCanceled    bool     `json:"isCanceled"`
+	IsComplete    bool     `json:"isComplete"`
+	DueDate       int64    `json:"dueDate"`
+	PassedTime    int64    `json:"passedTime"`
+	LastStartTime int64    `json:"lastStartTime"`
+}
+
+// ImportResult describes what happened with single imported item
+type ImportResult struct {
+	SourceId int
+	// Id assigned in repository, differs from SourceId when it was already taken
+	Id int
+	// Skipped holds reason why item was not imported
+	Skipped string
+}
+
+// ParseTaskbook reads taskline storage (top level array) as well as
+// taskbook storage (object keyed by id)
+func ParseTaskbook(data []byte) ([]TaskbookItem, error) {
+	var items []TaskbookItem
+	if err := json.Unmarshal(data, &items); err == nil {
+		return items, nil
+	}
+	var byId map[string]TaskbookItem
+	if err := json.Unmarshal(data, &byId); err != nil {
+		return nil, errors.WithMessage(err, "Failed to unmarshal taskbook storage")
+	}
+	for _, item := range byId {
+		items = append(items, item)
+	}
+	sort.Slice(items, func(i, j int) bool { return items[i].Id < items[j].Id })
+	return items, nil
+}
+
+// ToTask maps taskbook item into task
+func (item TaskbookItem) ToTask() Task {
+	t := Task{
+		Id:          item.Id,
+		Description: item.Description,
+		Boards:      append([]string(nil), item.Boards...),
+		Status:      legacyStatus(item.InProgress, item.IsCanceled, item.IsComplete),
+		IsStarred:   item.IsStarred,
+		IsNote:      !item.IsTask,
+		PassedTime:  time.Duration(item.PassedTime) * time.Millisecond,
+	}
+	if ValidatePriority(item.Priority) == nil {
+		t.Priority = item.Priority
+	}
+	if t.Status == StatusInProgress && item.LastStartTime > 0 {
+		t.StartedAt = time.Unix(0, item.LastStartTime*int64(time.Millisecond))
+	}
+	if item.Timestamp > 0 {
+		t.Date = time.Unix(0, item.Timestamp*int64(time.Millisecond))
+	} else if date, err := time.ParseInLocation(taskbookDateLayout, item.Date, time.Local); err == nil {
+		t.Date = date
+	}
+	if item.DueDate > 0 {
+		t.DueDate = startOfDay(time.Unix(0, item.DueDate*int64(time.Millisecond)))
+	}
+	return t
+}
+
+// ImportTaskbook stores all taskbook items in single transaction. Ids are
+// preserved unless already taken by active or archived task, in which case
+// next free id is assigned.
+func (rep *Repository) ImportTaskbook(data []byte) ([]ImportResult, error) {
+	items, err := ParseTaskbook(data)
+	if err != nil {
+		return nil, err
+	}
+	var results []ImportResult
+	err = rep.store.Transaction(func(tx Store) error {
+		results = nil
+		for _, item := range items {
+			result := ImportResult{SourceId: item.Id}
+			if strings.TrimSpace(item.Description) == "" {
+				result.Skipped = "empty description"
+				results = append(results, result)
+				continue
+			}
+			t := item.ToTask()
+			if t.Date.IsZero() {
+				t.Date = time.Now()
+			}
+			t.record(EventImported, time.Now(), "taskbook item %d", item.Id)
+			if len(t.Boards) == 0 {
+				t.Boards = []string{DefaultBoard}
+			}
+			taken, err := rep.idTaken(tx, t.Id)
+			if err != nil {
+				return err
+			}
+			if taken {
+				t.Id = 0
+				if err := rep.assignId(tx, &t); err != nil {
+					return err
+				}
+			}
+			created, err := tx.Create(t)
+			if err != nil {
+				return errors.WithMessagef(err, "Failed to import item: %d", item.Id)
+			}
+			result.Id = created.Id
+			results = append(results, result)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return results, nil
+}
@@ -0,0 +1,79 @@
+package task
+
+import (
+	"github.com/stretchr/testify/assert"
+	"io/ioutil"
+	"testing"
+	"time"
+)
+
+func TestImportTaskbook_ExampleStorage(t *testing.T) {
+	data, err := ioutil.ReadFile("../../../examples/storage.json")
+	assert.NoError(t, err)
+	repository := newTestRepository()
+
+	results, err := repository.ImportTaskbook(data)
+	assert.NoError(t, err)
+	assert.Equal(t, 2, len(results))
+
+	loaded, err := repository.Get(2)
+	assert.NoError(t, err)
+	assert.Equal(t, "Code taskline app", loaded.Description)
+	assert.Equal(t, []string{"My Board"}, loaded.Boards)
+	assert.Equal(t, int64(1623185209441), loaded.Date.UnixNano()/1e6)
+}
+
+func TestImportTaskbook_RemapsAndSkips(t *testing.T) {
+	repository := newTestRepository(Task{Id: 1, Description: "Existing"})
+	data := []byte(`{
+		"1": {"id": 1, "date": "Tue Jun 08 2021", "isTask": true, "description": "Conflicting", "boards": ["Work"], "isCanceled": true, "isStarred": true},
+		"5": {"id": 5, "date": "Tue Jun 08 2021", "isTask": false, "description": "Just a note", "boards": ["Work"]},
+		"4": {"id": 4, "date": "Wed Jun 09 2021", "isTask": true, "description": " ", "boards": []},
+		"3": {"id": 3, "date": "Wed Jun 09 2021", "isTask": true, "description": "Free id", "boards": [], "isComplete": true, "passedTime": 90000, "dueDate": 1623362400000}
+	}`)
+
+	results, err := repository.ImportTaskbook(data)
+	assert.NoError(t, err)
+	assert.Equal(t, []ImportResult{
+		{SourceId: 1, Id: 2},
+		{SourceId: 3, Id: 3},
+		{SourceId: 4, Skipped: "empty description"},
+		{SourceId: 5, Id: 5},
+	}, results)
+
+	note, err := repository.Get(5)
+	assert.NoError(t, err)
+	assert.Equal(t, "Just a note", note.Description)
+	assert.True(t, note.IsNote)
+
+	remapped, err := repository.Get(2)
+	assert.NoError(t, err)
+	assert.Equal(t, "Conflicting", remapped.Description)
+	assert.Equal(t, StatusCancelled, remapped.Status)
+	assert.True(t, remapped.IsStarred)
+
+	kept, err := repository.Get(3)
+	assert.NoError(t, err)
+	assert.Equal(t, StatusDone, kept.Status)
+	assert.Equal(t, []string{DefaultBoard}, kept.Boards)
+	assert.Equal(t, 2021, kept.Date.Year())
+	assert.Equal(t, 90*time.Second, kept.PassedTime)
+	assert.True(t, kept.HasDue())
+	assert.Equal(t, 2021, kept.DueDate.Year())
+	assert.Equal(t, 0, kept.DueDate.Hour())
+}
+
+func TestImportTaskbook_KeepsArchivedIds(t *testing.T) {
+	data, err := ioutil.ReadFile("../../../examples/storage.json")
+	assert.NoError(t, err)
+	repository := NewRepositoryWithArchive(NewMemoryStore(Task{Id: 1, Description: "Existing"}),
+		NewMemoryStore(Task{Id: 2, Description: "Archived", Status: StatusDone}, Task{Id: 4, Description: "Archived later"}))
+
+	results, err := repository.ImportTaskbook(data)
+	assert.NoError(t, err)
+	assert.Equal(t, []ImportResult{{SourceId: 1, Id: 5}, {SourceId: 2, Id: 6}}, results)
+
+	restored, err := repository.RestoreAll([]int{2})
+	assert.NoError(t, err)
+	assert.Equal(t, []Result{{Id: 2}}, restored)
+}